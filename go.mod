@@ -5,6 +5,7 @@ go 1.15
 require (
 	github.com/aws/aws-sdk-go v1.37.16
 	github.com/chanzuckerberg/go-misc v0.0.0-20201222183624-ac0eadedb39c
+	github.com/dsnet/compress v0.0.1
 	github.com/hashicorp/terraform-plugin-sdk v1.17.2
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.7.0
@@ -1,16 +1,35 @@
 package aws
 
 import (
+	"os"
+	"sync"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
 )
 
 // Client is an AWS client
 type Client struct {
-	KMS KMS
+	KMS    KMS
+	STS    STS
+	Region string
+
+	// RegionalKMS are the kms clients for regions other than Region
+	RegionalKMS map[string]KMS
+
+	session        *session.Session
+	creds          *credentials.Credentials
+	callerIdentity *sts.GetCallerIdentityOutput
+	mu             sync.Mutex
 }
 
 // NewClient returns a new aws client
@@ -20,25 +39,279 @@ func NewClient(d *schema.ResourceData) (*Client, error) {
 	if regionOverride != "" {
 		region = aws.String(regionOverride)
 	}
-	sess := session.Must(session.NewSessionWithOptions(
+	retryer, err := newRetryer(d)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := session.NewSessionWithOptions(
 		session.Options{
 			Config: aws.Config{
-				Region: region,
+				Region:           region,
+				EndpointResolver: endpointResolver(d, regionOverride),
+				Credentials:      staticCredentials(d),
+				Retryer:          retryer,
 			},
 			SharedConfigState: session.SharedConfigEnable,
+			SharedConfigFiles: sharedConfigFiles(d),
 			Profile:           d.Get("profile").(string),
 		},
-	))
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create aws session")
+	}
+	// every client of the session and its copies shares the rate limiter
+	if requestsPerSecond := d.Get("max_requests_per_second").(int); requestsPerSecond > 0 {
+		sess.Handlers.Send.PushFrontNamed(NewRateLimiter(float64(requestsPerSecond), requestsPerSecond).Handler())
+	}
+	if creds := webIdentityCredentials(sess, d); creds != nil {
+		sess = sess.Copy(&aws.Config{Credentials: creds})
+	}
 
-	var creds *credentials.Credentials
+	if !d.Get("skip_region_validation").(bool) {
+		err = validateRegion(aws.StringValue(sess.Config.Region))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	if r, ok := d.Get("role_arn").(string); ok && r != "" {
-		creds = stscreds.NewCredentials(sess, r)
+	creds, err := assumeRoleCredentials(sess, d)
+	if err != nil {
+		return nil, err
 	}
 
 	client := &Client{
-		KMS: NewKMS(sess, creds),
+		KMS:     NewKMS(sess, creds),
+		STS:     NewSTS(sess, creds),
+		Region:  aws.StringValue(sess.Config.Region),
+		session: sess,
+		creds:   creds,
+	}
+
+	// fail fast on bad credentials, unless sts is not reachable where kms is,
+	// for example in a vpc with only a kms endpoint
+	if !d.Get("skip_credentials_validation").(bool) {
+		_, err = client.CallerIdentity()
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not validate aws credentials")
+		}
 	}
 
 	return client, nil
 }
+
+// newRetryer returns the retryer configured by max_retries, retry_min_delay
+// and retry_max_delay
+func newRetryer(d *schema.ResourceData) (Retryer, error) {
+	minDelay, err := time.ParseDuration(d.Get("retry_min_delay").(string))
+	if err != nil {
+		return Retryer{}, errors.Wrap(err, "Could not parse retry_min_delay")
+	}
+	maxDelay, err := time.ParseDuration(d.Get("retry_max_delay").(string))
+	if err != nil {
+		return Retryer{}, errors.Wrap(err, "Could not parse retry_max_delay")
+	}
+	if minDelay > maxDelay {
+		return Retryer{}, errors.Errorf("retry_min_delay %s is longer than retry_max_delay %s", minDelay, maxDelay)
+	}
+	return NewRetryer(d.Get("max_retries").(int), minDelay, maxDelay), nil
+}
+
+// staticCredentials returns the access_key credentials, nil to use the
+// default credential chain
+func staticCredentials(d *schema.ResourceData) *credentials.Credentials {
+	accessKey := d.Get("access_key").(string)
+	if accessKey == "" {
+		return nil
+	}
+	return credentials.NewStaticCredentials(accessKey, d.Get("secret_key").(string), d.Get("token").(string))
+}
+
+// sharedConfigFiles returns the shared config file followed by the
+// shared_credentials_files, nil to use the default files
+func sharedConfigFiles(d *schema.ResourceData) []string {
+	files, ok := d.Get("shared_credentials_files").([]interface{})
+	if !ok || len(files) == 0 {
+		return nil
+	}
+
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = defaults.SharedConfigFilename()
+	}
+	// later files take precedence
+	sharedConfigFiles := []string{configFile}
+	for _, file := range files {
+		sharedConfigFiles = append(sharedConfigFiles, file.(string))
+	}
+	return sharedConfigFiles
+}
+
+// webIdentityToken is a web identity token set in the provider config
+type webIdentityToken string
+
+// FetchToken returns the token
+func (t webIdentityToken) FetchToken(credentials.Context) ([]byte, error) {
+	return []byte(t), nil
+}
+
+// webIdentityCredentials returns credentials for the
+// assume_role_with_web_identity role, nil when there is none
+func webIdentityCredentials(sess *session.Session, d *schema.ResourceData) *credentials.Credentials {
+	webIdentities, ok := d.Get("assume_role_with_web_identity").([]interface{})
+	if !ok || len(webIdentities) == 0 || webIdentities[0] == nil {
+		return nil
+	}
+	webIdentity := webIdentities[0].(map[string]interface{})
+
+	var token stscreds.TokenFetcher = stscreds.FetchTokenPath(webIdentity["web_identity_token_file"].(string))
+	if v, ok := webIdentity["web_identity_token"].(string); ok && v != "" {
+		token = webIdentityToken(v)
+	}
+	return credentials.NewCredentials(stscreds.NewWebIdentityRoleProviderWithToken(
+		sts.New(sess),
+		webIdentity["role_arn"].(string),
+		webIdentity["session_name"].(string),
+		token,
+	))
+}
+
+// assumeRoleCredentials returns credentials for the role_arn or assume_role
+// role, nil when there is no role to assume
+func assumeRoleCredentials(sess *session.Session, d *schema.ResourceData) (*credentials.Credentials, error) {
+	if r, ok := d.Get("role_arn").(string); ok && r != "" {
+		return stscreds.NewCredentials(sess, r), nil
+	}
+
+	assumeRoles, ok := d.Get("assume_role").([]interface{})
+	if !ok || len(assumeRoles) == 0 || assumeRoles[0] == nil {
+		return nil, nil
+	}
+	assumeRole := assumeRoles[0].(map[string]interface{})
+
+	var duration time.Duration
+	if v, ok := assumeRole["duration"].(string); ok && v != "" {
+		var err error
+		duration, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not parse assume_role duration")
+		}
+	}
+
+	return stscreds.NewCredentials(sess, assumeRole["role_arn"].(string), func(p *stscreds.AssumeRoleProvider) {
+		if v, ok := assumeRole["session_name"].(string); ok && v != "" {
+			p.RoleSessionName = v
+		}
+		if v, ok := assumeRole["external_id"].(string); ok && v != "" {
+			p.ExternalID = aws.String(v)
+		}
+		if v, ok := assumeRole["policy"].(string); ok && v != "" {
+			p.Policy = aws.String(v)
+		}
+		if duration > 0 {
+			p.Duration = duration
+		}
+		if v, ok := assumeRole["mfa_serial"].(string); ok && v != "" {
+			p.SerialNumber = aws.String(v)
+			p.TokenCode = aws.String(assumeRole["mfa_token"].(string))
+		}
+	}), nil
+}
+
+// endpointResolver resolves kms and sts in region to the endpoints overrides,
+// for example LocalStack or VPC interface endpoints. VPC interface endpoints
+// are regional, so other regions and services use the default endpoints.
+func endpointResolver(d *schema.ResourceData, region string) endpoints.Resolver {
+	overrides := map[string]string{}
+	for _, service := range []string{kms.EndpointsID, sts.EndpointsID} {
+		url, ok := d.Get("endpoints.0." + service).(string)
+		if ok && url != "" {
+			overrides[service] = url
+		}
+	}
+
+	return endpoints.ResolverFunc(func(service, r string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		if url, ok := overrides[service]; ok && r == region {
+			return endpoints.ResolvedEndpoint{
+				URL:           url,
+				SigningRegion: r,
+			}, nil
+		}
+		return endpoints.DefaultResolver().EndpointFor(service, r, opts...)
+	})
+}
+
+// validateRegion errors if region is not a region of a known partition,
+// including regions newer than the sdk that match a partition's region regex
+func validateRegion(region string) error {
+	if _, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); !ok {
+		return errors.Errorf("Invalid aws region %s, set skip_region_validation to use it anyway", region)
+	}
+	return nil
+}
+
+// KMSForRegion returns a kms client for region built from the same session
+// and credentials as the default client
+func (c *Client) KMSForRegion(region string) (KMS, error) {
+	if region == "" || region == c.Region {
+		return c.KMS, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if k, ok := c.RegionalKMS[region]; ok {
+		return k, nil
+	}
+	if c.session == nil {
+		return KMS{}, errors.Errorf("No session to create a kms client for region %s", region)
+	}
+	if c.RegionalKMS == nil {
+		c.RegionalKMS = map[string]KMS{}
+	}
+	k := NewKMS(c.session.Copy(&aws.Config{Region: aws.String(region)}), c.creds)
+	c.RegionalKMS[region] = k
+	return k, nil
+}
+
+// CallerIdentity returns the account and arn of the credentials in use, the
+// result is cached
+func (c *Client) CallerIdentity() (*sts.GetCallerIdentityOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.callerIdentity != nil {
+		return c.callerIdentity, nil
+	}
+	callerIdentity, err := c.STS.GetCallerIdentity()
+	if err != nil {
+		return nil, err
+	}
+	c.callerIdentity = callerIdentity
+	return callerIdentity, nil
+}
+
+// ValidateAccountID errors when the account of the credentials is not one of
+// allowed or is one of forbidden, empty lists allow every account
+func (c *Client) ValidateAccountID(allowed []string, forbidden []string) error {
+	if len(allowed) == 0 && len(forbidden) == 0 {
+		return nil
+	}
+	callerIdentity, err := c.CallerIdentity()
+	if err != nil {
+		return errors.Wrap(err, "Could not validate the aws account id")
+	}
+	accountID := aws.StringValue(callerIdentity.Account)
+
+	for _, forbiddenAccountID := range forbidden {
+		if accountID == forbiddenAccountID {
+			return errors.Errorf("aws account %s is forbidden by forbidden_account_ids", accountID)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, allowedAccountID := range allowed {
+		if accountID == allowedAccountID {
+			return nil
+		}
+	}
+	return errors.Errorf("aws account %s is not one of allowed_account_ids", accountID)
+}
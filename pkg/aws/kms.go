@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kms"
@@ -21,12 +22,86 @@ func NewKMS(s *session.Session, creds *credentials.Credentials) KMS {
 	return KMS{kms.New(s, &aws.Config{Credentials: creds})}
 }
 
-// EncryptBytes encrypts the plaintext using the keyID key, result is base64 encoded
-func (k *KMS) EncryptBytes(plaintext []byte, keyID string) (string, error) {
+// EncryptBytes encrypts the plaintext using the keyID key bound to the optional
+// encryption context, result is base64 encoded
+func (k *KMS) EncryptBytes(plaintext []byte, keyID string, encryptionContext map[string]string) (string, error) {
 	input := &kms.EncryptInput{}
 	input.SetKeyId(keyID).SetPlaintext(plaintext)
+	if len(encryptionContext) > 0 {
+		input.SetEncryptionContext(aws.StringMap(encryptionContext))
+	}
 	response, err := k.Svc.Encrypt(input)
+	if err != nil {
+		return "", errors.Wrap(err, "Could not encrypt password")
+	}
+	return base64.StdEncoding.EncodeToString(response.CiphertextBlob), nil
+}
+
+// ReEncryptBytes re-encrypts the base64 encoded ciphertext under destinationKeyID
+// without exposing the plaintext, result is base64 encoded
+func (k *KMS) ReEncryptBytes(
+	ciphertext string,
+	sourceEncryptionContext map[string]string,
+	destinationKeyID string,
+	destinationEncryptionContext map[string]string) (string, error) {
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", errors.Wrap(err, "Could not base64 decode ciphertext")
+	}
+	input := &kms.ReEncryptInput{}
+	input.SetCiphertextBlob(ciphertextBlob).SetDestinationKeyId(destinationKeyID)
+	if len(sourceEncryptionContext) > 0 {
+		input.SetSourceEncryptionContext(aws.StringMap(sourceEncryptionContext))
+	}
+	if len(destinationEncryptionContext) > 0 {
+		input.SetDestinationEncryptionContext(aws.StringMap(destinationEncryptionContext))
+	}
+	response, err := k.Svc.ReEncrypt(input)
+	if err != nil {
+		return "", errors.Wrap(err, "Could not re-encrypt password")
+	}
+	return base64.StdEncoding.EncodeToString(response.CiphertextBlob), nil
+}
+
+// DecryptBytes decrypts the base64 encoded ciphertext bound to the optional encryption context
+func (k *KMS) DecryptBytes(ciphertext string, encryptionContext map[string]string) ([]byte, error) {
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not base64 decode ciphertext")
+	}
+	input := &kms.DecryptInput{}
+	input.SetCiphertextBlob(ciphertextBlob)
+	if len(encryptionContext) > 0 {
+		input.SetEncryptionContext(aws.StringMap(encryptionContext))
+	}
+	response, err := k.Svc.Decrypt(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not decrypt password")
+	}
+	return response.Plaintext, nil
+}
+
+// KeyState returns the state of the keyID key, for example Enabled or PendingDeletion
+func (k *KMS) KeyState(keyID string) (string, error) {
+	input := &kms.DescribeKeyInput{}
+	input.SetKeyId(keyID)
+	response, err := k.Svc.DescribeKey(input)
+	if err != nil {
+		return "", errors.Wrapf(err, "Could not describe kms key %s", keyID)
+	}
+	return aws.StringValue(response.KeyMetadata.KeyState), nil
+}
 
-	return base64.StdEncoding.EncodeToString(response.CiphertextBlob),
-		errors.Wrap(err, "Could not encrypt password")
+// IsErrorCode returns true if the cause of err is an aws error with one of codes
+func IsErrorCode(err error, codes ...string) bool {
+	awsErr, ok := errors.Cause(err).(awserr.Error)
+	if !ok {
+		return false
+	}
+	for _, code := range codes {
+		if awsErr.Code() == code {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers the hashes kms signs
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"io"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// KMSSigner is an ssh.AlgorithmSigner backed by an asymmetric kms key,
+// the private key never leaves kms
+type KMSSigner struct {
+	kms       KMS
+	keyID     string
+	publicKey ssh.PublicKey
+
+	// cryptoPublicKey is the parsed kms public key, either *rsa.PublicKey or *ecdsa.PublicKey
+	cryptoPublicKey crypto.PublicKey
+}
+
+var _ ssh.AlgorithmSigner = &KMSSigner{}
+
+// ecdsaSignature is the DER encoded signature kms returns for ecdsa keys
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// NewKMSSigner returns a signer for the keyID SIGN_VERIFY kms key
+func NewKMSSigner(k KMS, keyID string) (*KMSSigner, error) {
+	input := &kms.GetPublicKeyInput{}
+	input.SetKeyId(keyID)
+	response, err := k.Svc.GetPublicKey(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not get public key of kms key %s", keyID)
+	}
+	if aws.StringValue(response.KeyUsage) != kms.KeyUsageTypeSignVerify {
+		return nil, errors.Errorf("kms key %s has key usage %s, signing needs %s",
+			keyID, aws.StringValue(response.KeyUsage), kms.KeyUsageTypeSignVerify)
+	}
+	cryptoPublicKey, err := x509.ParsePKIXPublicKey(response.PublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not parse public key of kms key %s", keyID)
+	}
+	switch cryptoPublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, errors.Errorf("kms key %s has unsupported public key type %T", keyID, cryptoPublicKey)
+	}
+	publicKey, err := ssh.NewPublicKey(cryptoPublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not generate openssh public key for kms key %s", keyID)
+	}
+	return &KMSSigner{
+		kms:             k,
+		keyID:           keyID,
+		publicKey:       publicKey,
+		cryptoPublicKey: cryptoPublicKey,
+	}, nil
+}
+
+// PublicKey returns the ssh public key of the kms key
+func (s *KMSSigner) PublicKey() ssh.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs data with the default algorithm of the key, rsa keys use rsa-sha2-512
+// since kms does not support SHA-1
+func (s *KMSSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+// SignWithAlgorithm signs data through kms, rand is unused
+func (s *KMSSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	switch pub := s.cryptoPublicKey.(type) {
+	case *rsa.PublicKey:
+		return s.signRSA(data, algorithm)
+	case *ecdsa.PublicKey:
+		return s.signECDSA(data, algorithm, pub.Curve)
+	default:
+		return nil, errors.Errorf("Unsupported public key type %T", pub)
+	}
+}
+
+func (s *KMSSigner) signRSA(data []byte, algorithm string) (*ssh.Signature, error) {
+	var hash crypto.Hash
+	var signingAlgorithm string
+	switch algorithm {
+	case "", ssh.SigAlgoRSASHA2512:
+		algorithm = ssh.SigAlgoRSASHA2512
+		hash = crypto.SHA512
+		signingAlgorithm = kms.SigningAlgorithmSpecRsassaPkcs1V15Sha512
+	case ssh.SigAlgoRSASHA2256:
+		hash = crypto.SHA256
+		signingAlgorithm = kms.SigningAlgorithmSpecRsassaPkcs1V15Sha256
+	default:
+		return nil, errors.Errorf("Unsupported signature algorithm %s for kms rsa key", algorithm)
+	}
+	signature, err := s.sign(data, hash, signingAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.Signature{
+		Format: algorithm,
+		Blob:   signature,
+	}, nil
+}
+
+func (s *KMSSigner) signECDSA(data []byte, algorithm string, curve elliptic.Curve) (*ssh.Signature, error) {
+	if algorithm != "" && algorithm != s.publicKey.Type() {
+		return nil, errors.Errorf("Unsupported signature algorithm %s for kms %s key", algorithm, s.publicKey.Type())
+	}
+
+	// the hash is fixed by the curve, see RFC 5656 section 6.2.1
+	var hash crypto.Hash
+	var signingAlgorithm string
+	switch curve.Params().BitSize {
+	case 256:
+		hash = crypto.SHA256
+		signingAlgorithm = kms.SigningAlgorithmSpecEcdsaSha256
+	case 384:
+		hash = crypto.SHA384
+		signingAlgorithm = kms.SigningAlgorithmSpecEcdsaSha384
+	case 521:
+		hash = crypto.SHA512
+		signingAlgorithm = kms.SigningAlgorithmSpecEcdsaSha512
+	default:
+		return nil, errors.Errorf("Unsupported curve %s", curve.Params().Name)
+	}
+	der, err := s.sign(data, hash, signingAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// kms returns an asn1 signature, ssh wants the mpints R and S
+	sig := ecdsaSignature{}
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not parse kms ecdsa signature")
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("Trailing data after kms ecdsa signature")
+	}
+	return &ssh.Signature{
+		Format: s.publicKey.Type(),
+		Blob:   ssh.Marshal(sig),
+	}, nil
+}
+
+// sign hashes data locally and has kms sign the digest, this keeps
+// messages over the kms 4096 byte limit signable
+func (s *KMSSigner) sign(data []byte, hash crypto.Hash, signingAlgorithm string) ([]byte, error) {
+	h := hash.New()
+	h.Write(data) // nolint
+	input := &kms.SignInput{}
+	input.SetKeyId(s.keyID).
+		SetMessage(h.Sum(nil)).
+		SetMessageType(kms.MessageTypeDigest).
+		SetSigningAlgorithm(signingAlgorithm)
+	response, err := s.kms.Svc.Sign(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not sign with kms key %s", s.keyID)
+	}
+	return response.Signature, nil
+}
@@ -0,0 +1,100 @@
+package aws_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeSigningKMS signs digests with a local key the way kms does
+type fakeSigningKMS struct {
+	kmsiface.KMSAPI
+	signer   crypto.Signer
+	keyUsage string
+}
+
+func (k *fakeSigningKMS) GetPublicKey(input *kms.GetPublicKeyInput) (*kms.GetPublicKeyOutput, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GetPublicKeyOutput{
+		KeyId:     input.KeyId,
+		KeyUsage:  awssdk.String(k.keyUsage),
+		PublicKey: der,
+	}, nil
+}
+
+func (k *fakeSigningKMS) Sign(input *kms.SignInput) (*kms.SignOutput, error) {
+	hashes := map[string]crypto.Hash{
+		kms.SigningAlgorithmSpecRsassaPkcs1V15Sha256: crypto.SHA256,
+		kms.SigningAlgorithmSpecRsassaPkcs1V15Sha512: crypto.SHA512,
+		kms.SigningAlgorithmSpecEcdsaSha256:          crypto.SHA256,
+		kms.SigningAlgorithmSpecEcdsaSha384:          crypto.SHA384,
+		kms.SigningAlgorithmSpecEcdsaSha512:          crypto.SHA512,
+	}
+	signature, err := k.signer.Sign(rand.Reader, input.Message, hashes[*input.SigningAlgorithm])
+	if err != nil {
+		return nil, err
+	}
+	return &kms.SignOutput{KeyId: input.KeyId, Signature: signature}, nil
+}
+
+func TestKMSSigner(t *testing.T) {
+	r := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	r.NoError(err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	r.NoError(err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	r.NoError(err)
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	r.NoError(err)
+
+	cases := []struct {
+		key        crypto.Signer
+		algorithm  string
+		wantFormat string
+	}{
+		{rsaKey, "", ssh.SigAlgoRSASHA2512},
+		{rsaKey, ssh.SigAlgoRSASHA2256, ssh.SigAlgoRSASHA2256},
+		{p256, "", ssh.KeyAlgoECDSA256},
+		{p384, "", ssh.KeyAlgoECDSA384},
+		{p521, ssh.KeyAlgoECDSA521, ssh.KeyAlgoECDSA521},
+	}
+	data := []byte("data to sign")
+	for _, c := range cases {
+		k := aws.KMS{Svc: &fakeSigningKMS{signer: c.key, keyUsage: kms.KeyUsageTypeSignVerify}}
+		signer, err := aws.NewKMSSigner(k, "key id")
+		r.NoError(err)
+
+		sig, err := signer.SignWithAlgorithm(rand.Reader, data, c.algorithm)
+		r.NoError(err)
+		r.Equal(c.wantFormat, sig.Format)
+		r.NoError(signer.PublicKey().Verify(data, sig))
+		r.Error(signer.PublicKey().Verify([]byte("other data"), sig))
+	}
+
+	// kms has no SHA-1 so ssh-rsa signatures are refused
+	k := aws.KMS{Svc: &fakeSigningKMS{signer: rsaKey, keyUsage: kms.KeyUsageTypeSignVerify}}
+	signer, err := aws.NewKMSSigner(k, "key id")
+	r.NoError(err)
+	_, err = signer.SignWithAlgorithm(rand.Reader, data, ssh.SigAlgoRSA)
+	r.Error(err)
+
+	k = aws.KMS{Svc: &fakeSigningKMS{signer: rsaKey, keyUsage: kms.KeyUsageTypeEncryptDecrypt}}
+	_, err = aws.NewKMSSigner(k, "key id")
+	r.Error(err)
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// RateLimiter is a token bucket limiting the requests per second
+type RateLimiter struct {
+	requestsPerSecond float64
+	burst             float64
+
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond with bursts
+// of up to burst requests
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		requestsPerSecond: requestsPerSecond,
+		burst:             float64(burst),
+		tokens:            float64(burst),
+		last:              time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.requestsPerSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// tokens go negative to queue waiting requests in order
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.requestsPerSecond * float64(time.Second))
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx aws.Context) error {
+	wait := l.reserve()
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handler returns a send handler rate limiting every attempt of a request,
+// retries included
+func (l *RateLimiter) Handler() request.NamedHandler {
+	return request.NamedHandler{
+		Name: "bless.RateLimiter",
+		Fn: func(r *request.Request) {
+			err := l.Wait(r.Context())
+			if err != nil {
+				r.Error = err
+			}
+		},
+	}
+}
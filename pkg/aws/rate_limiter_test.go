@@ -0,0 +1,45 @@
+package aws_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := require.New(t)
+	limiter := aws.NewRateLimiter(50, 5)
+
+	// the burst does not wait
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		r.NoError(limiter.Wait(context.Background()))
+	}
+	r.True(time.Since(start) < 20*time.Millisecond, "burst took %s", time.Since(start))
+
+	// concurrent requests share the rate, 10 more requests at 50/s take 200ms
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.NoError(limiter.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+	r.True(time.Since(start) >= 180*time.Millisecond, "rate limited requests took %s", time.Since(start))
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	r := require.New(t)
+	limiter := aws.NewRateLimiter(1, 1)
+	r.NoError(limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r.Equal(context.DeadlineExceeded, limiter.Wait(ctx))
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// kmsErrorRetryable classifies kms errors, retrying a terminal error only
+// delays the failure. Unlisted errors are classified by the sdk, which
+// retries throttling, 5xx and connection errors.
+var kmsErrorRetryable = map[string]bool{
+	// transient
+	"ThrottlingException":                 true,
+	kms.ErrCodeDependencyTimeoutException: true,
+	kms.ErrCodeInternalException:          true,
+	kms.ErrCodeKeyUnavailableException:    true,
+
+	// terminal, the request or the key has to change
+	"AccessDeniedException":                  false,
+	kms.ErrCodeDisabledException:             false,
+	kms.ErrCodeIncorrectKeyException:         false,
+	kms.ErrCodeInvalidArnException:           false,
+	kms.ErrCodeInvalidCiphertextException:    false,
+	kms.ErrCodeInvalidGrantTokenException:    false,
+	kms.ErrCodeInvalidKeyUsageException:      false,
+	kms.ErrCodeInvalidStateException:         false,
+	kms.ErrCodeKMSInvalidSignatureException:  false,
+	kms.ErrCodeLimitExceededException:        false,
+	kms.ErrCodeNotFoundException:             false,
+	kms.ErrCodeUnsupportedOperationException: false,
+}
+
+// Retryer retries transient errors with exponential backoff
+type Retryer struct {
+	client.DefaultRetryer
+}
+
+// NewRetryer returns a Retryer making up to maxRetries retries, waiting
+// between minDelay and maxDelay before each of them
+func NewRetryer(maxRetries int, minDelay time.Duration, maxDelay time.Duration) Retryer {
+	return Retryer{client.DefaultRetryer{
+		NumMaxRetries:    maxRetries,
+		MinRetryDelay:    minDelay,
+		MinThrottleDelay: minDelay,
+		MaxRetryDelay:    maxDelay,
+		MaxThrottleDelay: maxDelay,
+	}}
+}
+
+// ShouldRetry returns true if the request should be retried
+func (r Retryer) ShouldRetry(req *request.Request) bool {
+	if r.NumMaxRetries == 0 {
+		return false
+	}
+	if err, ok := req.Error.(awserr.Error); ok {
+		if retryable, ok := kmsErrorRetryable[err.Code()]; ok {
+			return retryable
+		}
+	}
+	return r.DefaultRetryer.ShouldRetry(req)
+}
@@ -0,0 +1,66 @@
+package aws_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryerShouldRetry(t *testing.T) {
+	r := require.New(t)
+
+	retryable := map[string]bool{
+		"ThrottlingException":          true,
+		"KMSInternalException":         true,
+		"DependencyTimeoutException":   true,
+		"KeyUnavailableException":      true,
+		"AccessDeniedException":        false,
+		"DisabledException":            false,
+		"InvalidCiphertextException":   false,
+		"KMSInvalidStateException":     false,
+		"LimitExceededException":       false,
+		"NotFoundException":            false,
+		"KMSInvalidSignatureException": false,
+		// not kms errors are classified by the sdk
+		"RequestTimeout":          true,
+		"ValidationError":         false,
+		"SomeUnknownServiceError": false,
+	}
+
+	retryer := aws.NewRetryer(3, time.Millisecond, time.Second)
+	for code, expected := range retryable {
+		req := &request.Request{
+			Error:        awserr.New(code, "message", nil),
+			HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+		}
+		r.Equal(expected, retryer.ShouldRetry(req), code)
+	}
+
+	noRetries := aws.NewRetryer(0, time.Millisecond, time.Second)
+	req := &request.Request{
+		Error:        awserr.New("ThrottlingException", "message", nil),
+		HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+	r.False(noRetries.ShouldRetry(req))
+}
+
+func TestRetryerRetryRules(t *testing.T) {
+	r := require.New(t)
+
+	retryer := aws.NewRetryer(10, 10*time.Millisecond, 100*time.Millisecond)
+	for retryCount := 0; retryCount < 10; retryCount++ {
+		req := &request.Request{
+			Error:        awserr.New("ThrottlingException", "message", nil),
+			HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+			RetryCount:   retryCount,
+		}
+		delay := retryer.RetryRules(req)
+		r.True(delay >= 10*time.Millisecond, "retry %d delay %s", retryCount, delay)
+		r.True(delay <= 100*time.Millisecond, "retry %d delay %s", retryCount, delay)
+	}
+}
@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/pkg/errors"
+)
+
+// STS is a sts client
+type STS struct {
+	Svc stsiface.STSAPI
+}
+
+// NewSTS returns a STS client
+func NewSTS(s *session.Session, creds *credentials.Credentials) STS {
+	return STS{sts.New(s, &aws.Config{Credentials: creds})}
+}
+
+// GetCallerIdentity returns the account and arn of the credentials in use
+func (s *STS) GetCallerIdentity() (*sts.GetCallerIdentityOutput, error) {
+	response, err := s.Svc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not get caller identity")
+	}
+	return response, nil
+}
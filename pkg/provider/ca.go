@@ -0,0 +1,693 @@
+package provider
+
+import (
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+// caSchema is the schema shared by every CA resource, extra holds the
+// resource specific arguments
+func caSchema(extra map[string]*schema.Schema) map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		schemaKmsKeyID: &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The kms key with which we should encrypt the CA password, changing it re-encrypts the password in place.",
+		},
+		schemaPrivateKeyFormat: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(util.PrivateKeyFormatPEMRFC1423),
+			Description:  "The format of the encrypted CA private key, one of pem_rfc1423, pkcs8_pbes2 or openssh.",
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(util.PrivateKeyFormats, false),
+		},
+		schemaCompression: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(util.CompressionNone),
+			Description:  "How the encrypted CA private key is compressed before it is base64 encoded, one of none, zlib or bz2.",
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(util.Compressions, false),
+		},
+		schemaEncryptionContext: &schema.Schema{
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The kms encryption context the CA password is bound to.",
+		},
+		schemaEncryptionContextFingerprint: &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Add the CA public key SHA256 fingerprint to the encryption context under the ca_fingerprint key.",
+			ForceNew:    true,
+		},
+		schemaVerifyOnRead: &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "On read decrypt the password through kms and check the CA private key still matches the public key.",
+		},
+		schemaRotationPeriod: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Replace the CA once this duration, for example 2160h, has elapsed since it was created.",
+			ValidateFunc: validateDuration,
+		},
+		schemaRotateAfter: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Replace the CA after this RFC3339 timestamp, if it was created before it.",
+			ValidateFunc: validation.IsRFC3339Time,
+		},
+		schemaKeepers: &schema.Schema{
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Arbitrary values that replace the CA when they change.",
+			ForceNew:    true,
+		},
+		schemaAdditionalKmsKeys: &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Additional kms keys, possibly in other regions, with which we should encrypt the CA password, changing them encrypts the password in place.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					schemaKmsKeyID: &schema.Schema{
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "The kms key with which we should encrypt the CA password.",
+					},
+					schemaRegion: &schema.Schema{
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "The region of the kms key.",
+					},
+					schemaName: &schema.Schema{
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The key of this password in encrypted_passwords, defaults to the region.",
+					},
+					schemaEncryptionContext: &schema.Schema{
+						Type:        schema.TypeMap,
+						Optional:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "The kms encryption context the CA password is bound to.",
+					},
+				},
+			},
+		},
+
+		// computed
+		schemaEncryptedPrivateKey: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the base64 encoded CA encrypted private key.",
+		},
+		schemaPublicKey: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the plaintext CA public key in openssh format.",
+		},
+		schemaFingerprintSHA256: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the OpenSSH SHA256 fingerprint of the CA public key.",
+		},
+		schemaFingerprintMD5: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the OpenSSH legacy MD5 fingerprint of the CA public key.",
+		},
+		schemaEncryptedPassword: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the kms encrypted password.",
+		},
+		schemaEffectiveEncryptionContext: &schema.Schema{
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "This is the full kms encryption context needed to decrypt the password.",
+		},
+		schemaEncryptedPasswords: &schema.Schema{
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "These are the kms encrypted passwords keyed by region, or name for additional kms keys.",
+		},
+		schemaCreatedAt: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the RFC3339 timestamp the CA was created at.",
+		},
+		schemaNextRotationAt: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the RFC3339 timestamp after which the CA is replaced, empty without rotation.",
+		},
+		schemaKmsKeyState: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the state of the kms key, for example Enabled, Disabled or PendingDeletion.",
+		},
+		schemaUnrecoverable: &schema.Schema{
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "This is true when the CA can no longer be recovered through kms and has to be replaced.",
+		},
+	}
+	for k, v := range extra {
+		s[k] = v
+	}
+	return s
+}
+
+// caResourceV0 is the CA schema of the releases before the CA arguments
+// existed, their state is at schema version 0
+func caResourceV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			schemaKmsKeyID: &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			schemaEncryptedPrivateKey: &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			schemaPublicKey: &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			schemaEncryptedPassword: &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// caStateV0 are the fixed values of the shared CA arguments in version 0
+var caStateV0 = map[string]interface{}{
+	schemaPrivateKeyFormat:             string(util.PrivateKeyFormatPEMRFC1423),
+	schemaCompression:                  string(util.CompressionNone),
+	schemaEncryptionContextFingerprint: false,
+	schemaVerifyOnRead:                 false,
+	// version 0 encrypted the password without an encryption context
+	schemaEffectiveEncryptionContext: map[string]interface{}{},
+}
+
+// caStateUpgraders upgrade version 0 state by filling in the arguments with
+// the fixed values those releases used, otherwise the defaults would replace
+// every existing CA. v0 holds the resource specific values.
+func caStateUpgraders(v0 map[string]interface{}) []schema.StateUpgrader {
+	return []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    caResourceV0().CoreConfigSchema().ImpliedType(),
+			Upgrade: func(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+				for k, v := range caStateV0 {
+					rawState[k] = v
+				}
+				for k, v := range v0 {
+					rawState[k] = v
+				}
+				return rawState, nil
+			},
+		},
+	}
+}
+
+// privateKeyFormat is the configured format of the encrypted CA private key
+func privateKeyFormat(d *schema.ResourceData) util.PrivateKeyFormat {
+	return util.PrivateKeyFormat(d.Get(schemaPrivateKeyFormat).(string))
+}
+
+// compression is the configured compression of the encrypted CA private key
+func compression(d *schema.ResourceData) util.Compression {
+	return util.Compression(d.Get(schemaCompression).(string))
+}
+
+// encryptionContext is the kms encryption context for the CA password
+func encryptionContext(d *schema.ResourceData, caFingerprint string) map[string]string {
+	return buildEncryptionContext(
+		d.Get(schemaEncryptionContext).(map[string]interface{}),
+		d.Get(schemaEncryptionContextFingerprint).(bool),
+		caFingerprint)
+}
+
+func buildEncryptionContext(configured map[string]interface{}, fingerprint bool, caFingerprint string) map[string]string {
+	context := map[string]string{}
+	for k, v := range configured {
+		context[k] = v.(string)
+	}
+	if fingerprint {
+		context[encryptionContextFingerprintKey] = caFingerprint
+	}
+	return context
+}
+
+// setFingerprints sets the fingerprints of the CA public key
+func setFingerprints(d *schema.ResourceData) error {
+	publicKey := d.Get(schemaPublicKey).(string)
+	fingerprintSHA256, err := util.FingerprintSHA256(publicKey)
+	if err != nil {
+		return err
+	}
+	fingerprintMD5, err := util.FingerprintMD5(publicKey)
+	if err != nil {
+		return err
+	}
+	d.Set(schemaFingerprintSHA256, fingerprintSHA256) // nolint
+	d.Set(schemaFingerprintMD5, fingerprintMD5)       // nolint
+	return nil
+}
+
+// stringMap converts a terraform map to a map of strings
+func stringMap(m map[string]interface{}) map[string]string {
+	converted := map[string]string{}
+	for k, v := range m {
+		converted[k] = v.(string)
+	}
+	return converted
+}
+
+// listOfStrings converts a terraform list to a slice of strings
+func listOfStrings(l []interface{}) []string {
+	converted := []string{}
+	for _, v := range l {
+		converted = append(converted, v.(string))
+	}
+	return converted
+}
+
+// keypairFunc generates the CA keypair from the resource configuration
+type keypairFunc func(d *schema.ResourceData) (*util.CA, error)
+
+// baseCA implements the lifecycle shared by every CA resource
+type baseCA struct {
+	createKeypair keypairFunc
+	importKey     importKeyFunc
+}
+
+// Create creates a CA
+func (ca *baseCA) Create(d *schema.ResourceData, meta interface{}) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+
+	kmsKeyID := d.Get(schemaKmsKeyID).(string)
+	keyPair, err := ca.createKeypair(d)
+	if err != nil {
+		return err
+	}
+	context := encryptionContext(d, keyPair.FingerprintSHA256)
+	encryptedPassword, err := awsClient.KMS.EncryptBytes(keyPair.Password, kmsKeyID, context)
+	if err != nil {
+		return err
+	}
+	additional, err := additionalKmsKeys(d.Get(schemaAdditionalKmsKeys).([]interface{}), awsClient.Region)
+	if err != nil {
+		return err
+	}
+	encryptedPasswords, err := ca.encryptAdditionalPasswords(d, awsClient, additional, keyPair.Password, keyPair.FingerprintSHA256)
+	if err != nil {
+		return err
+	}
+	encryptedPasswords[awsClient.Region] = encryptedPassword
+
+	d.Set(schemaEncryptedPrivateKey, keyPair.B64EncryptedPrivateKey) // nolint
+	d.Set(schemaPublicKey, keyPair.PublicKey)                        // nolint
+	d.Set(schemaFingerprintSHA256, keyPair.FingerprintSHA256)        // nolint
+	d.Set(schemaFingerprintMD5, keyPair.FingerprintMD5)              // nolint
+	d.Set(schemaEncryptedPassword, encryptedPassword)                // nolint
+	d.Set(schemaEffectiveEncryptionContext, context)                 // nolint
+	d.Set(schemaEncryptedPasswords, encryptedPasswords)              // nolint
+	err = setRotation(d, time.Now())
+	if err != nil {
+		return err
+	}
+	d.SetId(util.HashForState(keyPair.PublicKey))
+	return ca.Read(d, meta)
+}
+
+// Read checks that the CA can still be recovered through kms
+func (ca *baseCA) Read(d *schema.ResourceData, meta interface{}) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+
+	// CAs created before we tracked created_at start their rotation period now
+	if d.Get(schemaCreatedAt).(string) == "" {
+		err := setRotation(d, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	// CAs created before we tracked fingerprints derive them from the public key
+	if d.Get(schemaFingerprintSHA256).(string) == "" {
+		err := setFingerprints(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	// CAs created before we tracked encrypted_passwords only have the primary password
+	if len(d.Get(schemaEncryptedPasswords).(map[string]interface{})) == 0 {
+		d.Set(schemaEncryptedPasswords, map[string]string{awsClient.Region: d.Get(schemaEncryptedPassword).(string)}) // nolint
+	}
+
+	kmsKeyID := d.Get(schemaKmsKeyID).(string)
+	keyState, err := awsClient.KMS.KeyState(kmsKeyID)
+	if aws.IsErrorCode(err, kms.ErrCodeNotFoundException) {
+		log.Printf("[WARN] kms key %s not found, CA %s can no longer be recovered", kmsKeyID, d.Id())
+		d.Set(schemaKmsKeyState, "")     // nolint
+		d.Set(schemaUnrecoverable, true) // nolint
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d.Set(schemaKmsKeyState, keyState) // nolint
+
+	unrecoverable := false
+	switch {
+	case keyState == kms.KeyStatePendingDeletion:
+		log.Printf("[WARN] kms key %s is pending deletion, CA %s can no longer be recovered", kmsKeyID, d.Id())
+		unrecoverable = true
+	case keyState == kms.KeyStateEnabled && d.Get(schemaVerifyOnRead).(bool):
+		recoverable, err := ca.verify(d, awsClient)
+		if err != nil {
+			return err
+		}
+		unrecoverable = !recoverable
+	}
+	d.Set(schemaUnrecoverable, unrecoverable) // nolint
+	return nil
+}
+
+// Update updates the CA in place, a new kms key, encryption context or
+// additional kms key re-encrypts the password without touching the CA itself
+func (ca *baseCA) Update(d *schema.ResourceData, meta interface{}) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+
+	// keep the previous state if re-encryption fails
+	d.Partial(true)
+	// the diff marks encrypted_passwords computed, the state holds the current passwords
+	o, _ := d.GetChange(schemaEncryptedPasswords)
+	encryptedPasswords := stringMap(o.(map[string]interface{}))
+	if d.HasChanges(schemaKmsKeyID, schemaEncryptionContext) {
+		err := ca.reEncryptPassword(d, awsClient, encryptedPasswords)
+		if err != nil {
+			return err
+		}
+	}
+	if d.HasChange(schemaAdditionalKmsKeys) {
+		err := ca.updateAdditionalPasswords(d, awsClient, encryptedPasswords)
+		if err != nil {
+			return err
+		}
+	}
+	d.Set(schemaEncryptedPasswords, encryptedPasswords) // nolint
+	if d.HasChanges(schemaRotationPeriod, schemaRotateAfter) {
+		createdAt, err := time.Parse(time.RFC3339, d.Get(schemaCreatedAt).(string))
+		if err != nil {
+			return errors.Wrapf(err, "Could not parse %s", schemaCreatedAt)
+		}
+		err = setRotation(d, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+	d.Partial(false)
+	return ca.Read(d, meta)
+}
+
+// CustomizeDiff replaces the CA when it can no longer be recovered or is due for rotation
+func (ca *baseCA) CustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.Get(schemaUnrecoverable).(bool) {
+		err := d.SetNew(schemaUnrecoverable, false)
+		if err != nil {
+			return errors.Wrapf(err, "Could not set %s", schemaUnrecoverable)
+		}
+		return errors.Wrapf(d.ForceNew(schemaUnrecoverable), "Could not force %s", schemaUnrecoverable)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, d.Get(schemaCreatedAt).(string))
+	if err != nil {
+		// not refreshed since created_at was introduced, Read sets it
+		return nil
+	}
+	nextRotationAt, err := nextRotation(createdAt, d.Get(schemaRotationPeriod).(string), d.Get(schemaRotateAfter).(string))
+	if err != nil {
+		return err
+	}
+	if !nextRotationAt.IsZero() && !time.Now().Before(nextRotationAt) {
+		log.Printf("[INFO] CA %s is due for rotation since %s", d.Id(), formatTime(nextRotationAt))
+		err = d.SetNewComputed(schemaCreatedAt)
+		if err != nil {
+			return errors.Wrapf(err, "Could not set %s", schemaCreatedAt)
+		}
+		return errors.Wrapf(d.ForceNew(schemaCreatedAt), "Could not force %s", schemaCreatedAt)
+	}
+	if d.HasChange(schemaKmsKeyID) || d.HasChange(schemaEncryptionContext) {
+		for _, key := range []string{schemaEncryptedPassword, schemaEncryptedPasswords, schemaEffectiveEncryptionContext} {
+			err = d.SetNewComputed(key)
+			if err != nil {
+				return errors.Wrapf(err, "Could not set %s", key)
+			}
+		}
+	}
+	if d.HasChange(schemaAdditionalKmsKeys) {
+		err = d.SetNewComputed(schemaEncryptedPasswords)
+		if err != nil {
+			return errors.Wrapf(err, "Could not set %s", schemaEncryptedPasswords)
+		}
+	}
+	if d.HasChange(schemaRotationPeriod) || d.HasChange(schemaRotateAfter) {
+		return errors.Wrapf(d.SetNew(schemaNextRotationAt, formatTime(nextRotationAt)), "Could not set %s", schemaNextRotationAt)
+	}
+	return nil
+}
+
+// setRotation records when the CA was created and when it is due for rotation
+func setRotation(d *schema.ResourceData, createdAt time.Time) error {
+	nextRotationAt, err := nextRotation(createdAt, d.Get(schemaRotationPeriod).(string), d.Get(schemaRotateAfter).(string))
+	if err != nil {
+		return err
+	}
+	d.Set(schemaCreatedAt, formatTime(createdAt))           // nolint
+	d.Set(schemaNextRotationAt, formatTime(nextRotationAt)) // nolint
+	return nil
+}
+
+// nextRotation is when the CA is due for rotation, the earlier of createdAt
+// plus the rotation period and rotateAfter. rotateAfter only applies to CAs
+// created before it, so the CA replacing a rotated one is not rotated again.
+// It is zero without rotation.
+func nextRotation(createdAt time.Time, rotationPeriod string, rotateAfter string) (time.Time, error) {
+	var next time.Time
+	if rotationPeriod != "" {
+		period, err := time.ParseDuration(rotationPeriod)
+		if err != nil {
+			return next, errors.Wrapf(err, "Could not parse %s", schemaRotationPeriod)
+		}
+		next = createdAt.Add(period)
+	}
+	if rotateAfter != "" {
+		after, err := time.Parse(time.RFC3339, rotateAfter)
+		if err != nil {
+			return next, errors.Wrapf(err, "Could not parse %s", schemaRotateAfter)
+		}
+		if createdAt.Before(after) && (next.IsZero() || after.Before(next)) {
+			next = after
+		}
+	}
+	return next, nil
+}
+
+// formatTime formats t as RFC3339 in UTC, the zero time is empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Delete deletes the ca
+func (ca *baseCA) Delete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// additionalKmsKey is an additional kms key the CA password is encrypted with
+type additionalKmsKey struct {
+	kmsKeyID          string
+	region            string
+	encryptionContext map[string]interface{}
+}
+
+// additionalKmsKeys are the additional kms keys keyed by their name in encrypted_passwords
+func additionalKmsKeys(raw []interface{}, primaryRegion string) (map[string]additionalKmsKey, error) {
+	keys := map[string]additionalKmsKey{}
+	for _, r := range raw {
+		additional := r.(map[string]interface{})
+		region := additional[schemaRegion].(string)
+		name := additional[schemaName].(string)
+		if name == "" {
+			name = region
+		}
+		if _, ok := keys[name]; ok || name == primaryRegion {
+			return nil, errors.Errorf("Duplicate additional kms key name %s, set a unique name", name)
+		}
+		keys[name] = additionalKmsKey{
+			kmsKeyID:          additional[schemaKmsKeyID].(string),
+			region:            region,
+			encryptionContext: additional[schemaEncryptionContext].(map[string]interface{}),
+		}
+	}
+	return keys, nil
+}
+
+// encryptAdditionalPasswords encrypts the CA password under every additional kms key
+func (ca *baseCA) encryptAdditionalPasswords(
+	d *schema.ResourceData,
+	awsClient *aws.Client,
+	keys map[string]additionalKmsKey,
+	password []byte,
+	caFingerprint string) (map[string]string, error) {
+	fingerprint := d.Get(schemaEncryptionContextFingerprint).(bool)
+	encryptedPasswords := map[string]string{}
+	for name, key := range keys {
+		kmsClient, err := awsClient.KMSForRegion(key.region)
+		if err != nil {
+			return nil, err
+		}
+		context := buildEncryptionContext(key.encryptionContext, fingerprint, caFingerprint)
+		encryptedPassword, err := kmsClient.EncryptBytes(password, key.kmsKeyID, context)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not encrypt password for %s", name)
+		}
+		encryptedPasswords[name] = encryptedPassword
+	}
+	return encryptedPasswords, nil
+}
+
+// updateAdditionalPasswords encrypts the CA password for added or changed
+// additional kms keys into encryptedPasswords and drops the passwords of removed ones
+func (ca *baseCA) updateAdditionalPasswords(d *schema.ResourceData, awsClient *aws.Client, encryptedPasswords map[string]string) error {
+	o, n := d.GetChange(schemaAdditionalKmsKeys)
+	previous, err := additionalKmsKeys(o.([]interface{}), awsClient.Region)
+	if err != nil {
+		return err
+	}
+	current, err := additionalKmsKeys(n.([]interface{}), awsClient.Region)
+	if err != nil {
+		return err
+	}
+
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			delete(encryptedPasswords, name)
+		}
+	}
+	changed := map[string]additionalKmsKey{}
+	for name, key := range current {
+		if _, ok := encryptedPasswords[name]; !ok || !reflect.DeepEqual(previous[name], key) {
+			changed[name] = key
+		}
+	}
+
+	if len(changed) > 0 {
+		// decrypt the primary password once for all added or changed keys
+		context := stringMap(d.Get(schemaEffectiveEncryptionContext).(map[string]interface{}))
+		password, err := awsClient.KMS.DecryptBytes(d.Get(schemaEncryptedPassword).(string), context)
+		if err != nil {
+			return err
+		}
+		caFingerprint, err := util.FingerprintSHA256(d.Get(schemaPublicKey).(string))
+		if err != nil {
+			return err
+		}
+		encrypted, err := ca.encryptAdditionalPasswords(d, awsClient, changed, password, caFingerprint)
+		if err != nil {
+			return err
+		}
+		for name, encryptedPassword := range encrypted {
+			encryptedPasswords[name] = encryptedPassword
+		}
+	}
+	return nil
+}
+
+// reEncryptPassword re-encrypts the password under the configured kms key and
+// encryption context into encryptedPasswords
+func (ca *baseCA) reEncryptPassword(d *schema.ResourceData, awsClient *aws.Client, encryptedPasswords map[string]string) error {
+	fingerprint, err := util.FingerprintSHA256(d.Get(schemaPublicKey).(string))
+	if err != nil {
+		return err
+	}
+	sourceContext := stringMap(d.Get(schemaEffectiveEncryptionContext).(map[string]interface{}))
+	destinationContext := encryptionContext(d, fingerprint)
+	encryptedPassword, err := awsClient.KMS.ReEncryptBytes(
+		d.Get(schemaEncryptedPassword).(string),
+		sourceContext,
+		d.Get(schemaKmsKeyID).(string),
+		destinationContext)
+	if err != nil {
+		return err
+	}
+
+	encryptedPasswords[awsClient.Region] = encryptedPassword
+	d.Set(schemaEncryptedPassword, encryptedPassword)           // nolint
+	d.Set(schemaEffectiveEncryptionContext, destinationContext) // nolint
+	return nil
+}
+
+// verify decrypts the CA through kms and checks it matches the public key,
+// it returns false when the CA can no longer be recovered
+func (ca *baseCA) verify(d *schema.ResourceData, awsClient *aws.Client) (bool, error) {
+	context := stringMap(d.Get(schemaEffectiveEncryptionContext).(map[string]interface{}))
+	password, err := awsClient.KMS.DecryptBytes(d.Get(schemaEncryptedPassword).(string), context)
+	if aws.IsErrorCode(err, kms.ErrCodeInvalidCiphertextException, kms.ErrCodeIncorrectKeyException) {
+		log.Printf("[WARN] CA %s password can no longer be decrypted: %s", d.Id(), err)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	signer, err := util.DecryptCA(d.Get(schemaEncryptedPrivateKey).(string), password)
+	if err != nil {
+		log.Printf("[WARN] CA %s private key can no longer be decrypted: %s", d.Id(), err)
+		return false, nil
+	}
+	matches, err := util.PublicKeyMatches(signer, d.Get(schemaPublicKey).(string))
+	if err != nil {
+		return false, err
+	}
+	if !matches {
+		log.Printf("[WARN] CA %s private key does not match its public key", d.Id())
+	}
+	return matches, nil
+}
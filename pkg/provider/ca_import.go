@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"crypto"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// importKeyFunc checks the imported private key is of the resource's type
+// and sets the resource specific attributes derived from it
+type importKeyFunc func(d *schema.ResourceData, signer crypto.Signer) error
+
+// caImport is the import document for an existing CA, the import id is either
+// the document itself or the path to a file holding it
+type caImport struct {
+	KmsKeyID          string            `json:"kms_key_id"`
+	EncryptedCA       string            `json:"encrypted_ca"`
+	EncryptedPassword string            `json:"encrypted_password"`
+	EncryptionContext map[string]string `json:"encryption_context"`
+}
+
+func parseCAImport(id string) (*caImport, error) {
+	raw := []byte(id)
+	if !strings.HasPrefix(strings.TrimSpace(id), "{") {
+		var err error
+		raw, err = ioutil.ReadFile(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not read import file %s", id)
+		}
+	}
+
+	imported := &caImport{}
+	err := json.Unmarshal(raw, imported)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not parse CA import json")
+	}
+	if imported.KmsKeyID == "" || imported.EncryptedCA == "" || imported.EncryptedPassword == "" {
+		return nil, errors.New("CA import json requires kms_key_id, encrypted_ca and encrypted_password")
+	}
+	return imported, nil
+}
+
+// Import imports an existing CA, its public key is derived by decrypting it through kms
+func (ca *baseCA) Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return nil, errors.New("meta is not of type *aws.Client")
+	}
+	imported, err := parseCAImport(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := util.DetectPrivateKeyFormat(imported.EncryptedCA)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := util.DetectCACompression(imported.EncryptedCA)
+	if err != nil {
+		return nil, err
+	}
+	password, err := awsClient.KMS.DecryptBytes(imported.EncryptedPassword, imported.EncryptionContext)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := util.DecryptCA(imported.EncryptedCA, password)
+	if err != nil {
+		return nil, err
+	}
+	err = ca.importKey(d, signer)
+	if err != nil {
+		return nil, err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(signer.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not generate openssh public key")
+	}
+	publicKey := string(ssh.MarshalAuthorizedKey(sshPublicKey))
+
+	// split the fingerprint we add ourselves back out of the configured context
+	configuredContext := map[string]string{}
+	fingerprint := false
+	for k, v := range imported.EncryptionContext {
+		if k == encryptionContextFingerprintKey && v == ssh.FingerprintSHA256(sshPublicKey) {
+			fingerprint = true
+			continue
+		}
+		configuredContext[k] = v
+	}
+
+	d.Set(schemaKmsKeyID, imported.KmsKeyID)                                                         // nolint
+	d.Set(schemaPrivateKeyFormat, string(format))                                                    // nolint
+	d.Set(schemaCompression, string(compression))                                                    // nolint
+	d.Set(schemaEncryptionContext, configuredContext)                                                // nolint
+	d.Set(schemaEncryptionContextFingerprint, fingerprint)                                           // nolint
+	d.Set(schemaVerifyOnRead, false)                                                                 // nolint
+	d.Set(schemaEncryptedPrivateKey, imported.EncryptedCA)                                           // nolint
+	d.Set(schemaPublicKey, publicKey)                                                                // nolint
+	d.Set(schemaFingerprintSHA256, ssh.FingerprintSHA256(sshPublicKey))                              // nolint
+	d.Set(schemaFingerprintMD5, ssh.FingerprintLegacyMD5(sshPublicKey))                              // nolint
+	d.Set(schemaEncryptedPassword, imported.EncryptedPassword)                                       // nolint
+	d.Set(schemaEffectiveEncryptionContext, imported.EncryptionContext)                              // nolint
+	d.Set(schemaEncryptedPasswords, map[string]string{awsClient.Region: imported.EncryptedPassword}) // nolint
+	d.SetId(util.HashForState(publicKey))
+	return []*schema.ResourceData{d}, nil
+}
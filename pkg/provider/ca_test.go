@@ -0,0 +1,393 @@
+package provider_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	blessaws "github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/provider"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReadDrift(t *testing.T) {
+	providers, kmsMock := getTestProviders()
+
+	decryptOutput := &kms.DecryptOutput{}
+	kmsMock.On("Encrypt", mock.Anything).Run(func(args mock.Arguments) {
+		decryptOutput.Plaintext = args.Get(0).(*kms.EncryptInput).Plaintext
+	}).Return(&kms.EncryptOutput{CiphertextBlob: []byte("ciphertext")}, nil)
+	kmsMock.On("Decrypt", mock.Anything).Return(decryptOutput, nil)
+	describeOutput := describeKeyOutput(kms.KeyStateEnabled)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeOutput, nil)
+
+	config := `
+	provider "bless" {
+		region = "us-east-1"
+	}
+
+	resource "bless_ca" "bless" {
+		kms_key_id     = "testo"
+		key_bits       = 2048
+		verify_on_read = true
+	}
+	`
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: config,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("bless_ca.bless", "kms_key_state", "Enabled"),
+					r.TestCheckResourceAttr("bless_ca.bless", "unrecoverable", "false"),
+				),
+			},
+			r.TestStep{
+				PreConfig: func() {
+					describeOutput.KeyMetadata.KeyState = aws.String(kms.KeyStatePendingDeletion)
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			r.TestStep{
+				PreConfig: func() {
+					describeOutput.KeyMetadata.KeyState = aws.String(kms.KeyStateEnabled)
+					decryptOutput.Plaintext = []byte("not the password")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestRotate(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	kmsMock.On("Encrypt", mock.Anything).Return(&kms.EncryptOutput{CiphertextBlob: []byte("ciphertext")}, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	config := func(rotation string, keeper string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		resource "bless_ca" "bless" {
+			kms_key_id = "testo"
+			key_bits   = 2048
+			%s
+			keepers = {
+				keeper = "%s"
+			}
+		}
+		`, rotation, keeper)
+	}
+
+	// after the CA of the third step is created, before the last step
+	rotateAfter := time.Now().Add(3 * time.Second).Truncate(time.Second)
+
+	var id string
+	checkID := func(replaced bool) r.TestCheckFunc {
+		return func(s *terraform.State) error {
+			current := s.RootModule().Resources["bless_ca.bless"].Primary.ID
+			if id != "" {
+				a.Equal(replaced, current != id)
+			}
+			id = current
+			return nil
+		}
+	}
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: config(`rotate_after = "2099-01-01T00:00:00Z"`, "1"),
+				Check: r.ComposeTestCheckFunc(
+					checkID(false),
+					r.TestMatchResourceAttr("bless_ca.bless", "created_at", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+					r.TestCheckResourceAttr("bless_ca.bless", "next_rotation_at", "2099-01-01T00:00:00Z"),
+				),
+			},
+			r.TestStep{
+				// changing the rotation schedule is done in place
+				Config: config(`
+				rotate_after    = "2099-01-01T00:00:00Z"
+				rotation_period = "87600h"
+				`, "1"),
+				Check: r.ComposeTestCheckFunc(
+					checkID(false),
+					r.TestMatchResourceAttr("bless_ca.bless", "next_rotation_at", regexp.MustCompile(`^20[3-4]\d-`)),
+				),
+			},
+			r.TestStep{
+				Config: config(`rotate_after = "2099-01-01T00:00:00Z"`, "2"),
+				Check:  checkID(true),
+			},
+			r.TestStep{
+				// a rotate_after before created_at does not rotate
+				Config: config(`rotate_after = "2000-01-01T00:00:00Z"`, "2"),
+				Check: r.ComposeTestCheckFunc(
+					checkID(false),
+					r.TestCheckResourceAttr("bless_ca.bless", "next_rotation_at", ""),
+				),
+			},
+			r.TestStep{
+				// due for rotation, the CA is replaced once and the plan after
+				// apply is empty
+				PreConfig: func() {
+					time.Sleep(time.Until(rotateAfter.Add(time.Second)))
+				},
+				Config: config(fmt.Sprintf(`rotate_after = "%s"`, rotateAfter.Format(time.RFC3339)), "2"),
+				Check: r.ComposeTestCheckFunc(
+					checkID(true),
+					r.TestCheckResourceAttr("bless_ca.bless", "next_rotation_at", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestUpdateKMSKey(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	kmsMock.On("Encrypt", mock.Anything).Return(&kms.EncryptOutput{CiphertextBlob: []byte("ciphertext")}, nil)
+	kmsMock.On("ReEncrypt", mock.MatchedBy(func(input *kms.ReEncryptInput) bool {
+		return string(input.CiphertextBlob) == "ciphertext" &&
+			aws.StringValue(input.DestinationKeyId) == "new-key" &&
+			aws.StringValue(input.DestinationEncryptionContext["service"]) == "bless"
+	})).Return(&kms.ReEncryptOutput{CiphertextBlob: []byte("reencrypted")}, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	var id, publicKey, encryptedCA string
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				resource "bless_ca" "bless" {
+					kms_key_id = "testo"
+					key_bits   = 2048
+
+					additional_kms_keys {
+						kms_key_id = "west"
+						region     = "us-west-2"
+					}
+				}
+			`,
+				Check: func(s *terraform.State) error {
+					primary := s.RootModule().Resources["bless_ca.bless"].Primary
+					id = primary.ID
+					publicKey = primary.Attributes["public_key"]
+					encryptedCA = primary.Attributes["encrypted_ca"]
+					return nil
+				},
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				resource "bless_ca" "bless" {
+					kms_key_id = "new-key"
+					key_bits   = 2048
+					encryption_context = {
+						service = "bless"
+					}
+
+					additional_kms_keys {
+						kms_key_id = "west"
+						region     = "us-west-2"
+					}
+				}
+			`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_password", base64.StdEncoding.EncodeToString([]byte("reencrypted"))),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.us-east-1", base64.StdEncoding.EncodeToString([]byte("reencrypted"))),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.us-west-2", base64.StdEncoding.EncodeToString([]byte("ciphertext"))),
+					r.TestCheckResourceAttr("bless_ca.bless", "effective_encryption_context.service", "bless"),
+					func(s *terraform.State) error {
+						primary := s.RootModule().Resources["bless_ca.bless"].Primary
+						a.Equal(id, primary.ID)
+						a.Equal(publicKey, primary.Attributes["public_key"])
+						a.Equal(encryptedCA, primary.Attributes["encrypted_ca"])
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestUpdateAdditionalKMSKeys(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	decryptOutput := &kms.DecryptOutput{}
+	kmsMock.On("Encrypt", mock.MatchedBy(func(input *kms.EncryptInput) bool {
+		return aws.StringValue(input.KeyId) == "testo"
+	})).Run(func(args mock.Arguments) {
+		decryptOutput.Plaintext = args.Get(0).(*kms.EncryptInput).Plaintext
+	}).Return(&kms.EncryptOutput{CiphertextBlob: []byte("primary")}, nil)
+	additional := func(keyID string, service string, ciphertext string) {
+		kmsMock.On("Encrypt", mock.MatchedBy(func(input *kms.EncryptInput) bool {
+			return aws.StringValue(input.KeyId) == keyID &&
+				aws.StringValue(input.EncryptionContext["service"]) == service &&
+				bytes.Equal(input.Plaintext, decryptOutput.Plaintext)
+		})).Return(&kms.EncryptOutput{CiphertextBlob: []byte(ciphertext)}, nil)
+	}
+	additional("west", "bless-west", "west")
+	additional("west", "bless-west-2", "west-2")
+	additional("west-dr", "", "west-dr")
+	kmsMock.On("Decrypt", mock.Anything).Return(decryptOutput, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	config := func(additionalKmsKeys string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		resource "bless_ca" "bless" {
+			kms_key_id = "testo"
+			key_bits   = 2048
+			%s
+		}
+		`, additionalKmsKeys)
+	}
+	west := func(service string) string {
+		return fmt.Sprintf(`
+			additional_kms_keys {
+				kms_key_id = "west"
+				region     = "us-west-2"
+				encryption_context = {
+					service = "%s"
+				}
+			}
+		`, service)
+	}
+	dr := `
+			additional_kms_keys {
+				kms_key_id = "west-dr"
+				region     = "us-west-2"
+				name       = "dr"
+			}
+	`
+
+	var id string
+	checkID := func(s *terraform.State) error {
+		current := s.RootModule().Resources["bless_ca.bless"].Primary.ID
+		if id != "" {
+			a.Equal(id, current)
+		}
+		id = current
+		return nil
+	}
+	encrypted := func(ciphertext string) string {
+		return base64.StdEncoding.EncodeToString([]byte(ciphertext))
+	}
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: config(west("bless-west")),
+				Check: r.ComposeTestCheckFunc(
+					checkID,
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.%", "2"),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.us-west-2", encrypted("west")),
+				),
+			},
+			r.TestStep{
+				// adding a key and changing another encrypt the password in place
+				Config: config(west("bless-west-2") + dr),
+				Check: r.ComposeTestCheckFunc(
+					checkID,
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.%", "3"),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.us-east-1", encrypted("primary")),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.us-west-2", encrypted("west-2")),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.dr", encrypted("west-dr")),
+				),
+			},
+			r.TestStep{
+				// removing a key drops its password
+				Config: config(dr),
+				Check: r.ComposeTestCheckFunc(
+					checkID,
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.%", "2"),
+					r.TestCheckNoResourceAttr("bless_ca.bless", "encrypted_passwords.us-west-2"),
+					r.TestCheckResourceAttr("bless_ca.bless", "encrypted_passwords.dr", encrypted("west-dr")),
+				),
+			},
+		},
+	})
+	kmsMock.AssertNumberOfCalls(t, "Decrypt", 1)
+}
+
+func TestUpgradeState(t *testing.T) {
+	a := assert.New(t)
+	kmsMock := &KMSMock{}
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+	client := &blessaws.Client{
+		KMS:    blessaws.KMS{Svc: kmsMock},
+		Region: "us-east-1",
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	ca, err := util.NewCA(privateKey, privateKey.Public(), 64, util.PrivateKeyFormatPEMRFC1423, util.CompressionNone)
+	a.NoError(err)
+
+	tests := []struct {
+		resourceType string
+		resource     *schema.Resource
+	}{
+		{"bless_ca", provider.CA()},
+		{"bless_ecdsa_ca", provider.ECDSACA()},
+	}
+	for _, test := range tests {
+		// the state written by releases before the CA arguments existed
+		state := &terraform.InstanceState{
+			ID: util.HashForState(ca.PublicKey),
+			Attributes: map[string]string{
+				"id":                 util.HashForState(ca.PublicKey),
+				"kms_key_id":         "testo",
+				"encrypted_ca":       ca.B64EncryptedPrivateKey,
+				"public_key":         ca.PublicKey,
+				"encrypted_password": base64.StdEncoding.EncodeToString([]byte("ciphertext")),
+			},
+		}
+		state, err := test.resource.Refresh(state, client)
+		a.NoError(err, test.resourceType)
+		a.Equal(state.Attributes["encrypted_password"], state.Attributes["encrypted_passwords.us-east-1"], test.resourceType)
+		diff, err := test.resource.Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"kms_key_id": "testo",
+		}), client)
+		a.NoError(err, test.resourceType)
+		a.True(diff.Empty(), "%s plan is not empty: %v", test.resourceType, diff)
+	}
+}
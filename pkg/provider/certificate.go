@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	schemaPrincipals       = "principals"
+	schemaCertificateKeyID = "key_id"
+	schemaValidityPeriod   = "validity_period"
+	schemaKmsSigningKeyID  = "kms_signing_key_id"
+	schemaCertificate      = "certificate"
+	schemaSerial           = "serial"
+	schemaValidAfter       = "valid_after"
+	schemaValidBefore      = "valid_before"
+	schemaCAPublicKey      = "ca_public_key"
+	schemaExtensions       = "extensions"
+	schemaCriticalOptions  = "critical_options"
+	schemaCertificateType  = "cert_type"
+	schemaEarlyRenewal     = "early_renewal"
+)
+
+// certificateCASources are the mutually exclusive ways of signing a certificate
+var certificateCASources = []string{schemaEncryptedPrivateKey, schemaKmsSigningKeyID}
+
+// certificateSchema is the schema shared by every certificate resource, extra
+// adds the resource specific arguments. Certificates are immutable so every
+// argument forces a new certificate.
+func certificateSchema(extra map[string]*schema.Schema) map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		schemaPublicKey: &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The public key to sign in authorized_keys format.",
+			ForceNew:     true,
+			ValidateFunc: validateAuthorizedKey,
+		},
+		schemaPrincipals: &schema.Schema{
+			Type:        schema.TypeList,
+			Required:    true,
+			MinItems:    1,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The principals the certificate is valid for.",
+			ForceNew:    true,
+		},
+		schemaCertificateKeyID: &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The key id of the certificate, it is logged by sshd when the certificate is used.",
+			ForceNew:    true,
+		},
+		schemaValidityPeriod: &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "How long the certificate is valid for, a duration such as 24h.",
+			ForceNew:     true,
+			ValidateFunc: validateDuration,
+		},
+
+		// the CA
+		schemaEncryptedPrivateKey: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The encrypted_ca of a CA resource, conflicts with kms_signing_key_id.",
+			ForceNew:     true,
+			ExactlyOneOf: certificateCASources,
+			RequiredWith: []string{schemaEncryptedPassword},
+		},
+		schemaEncryptedPassword: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "The encrypted_password of a CA resource.",
+			ForceNew:     true,
+			RequiredWith: []string{schemaEncryptedPrivateKey},
+		},
+		schemaEncryptionContext: &schema.Schema{
+			Type:          schema.TypeMap,
+			Optional:      true,
+			Elem:          &schema.Schema{Type: schema.TypeString},
+			Description:   "The effective_encryption_context of a CA resource.",
+			ForceNew:      true,
+			ConflictsWith: []string{schemaKmsSigningKeyID},
+		},
+		schemaKmsSigningKeyID: &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "An asymmetric SIGN_VERIFY kms key to sign with instead of a CA resource.",
+			ForceNew:     true,
+			ExactlyOneOf: certificateCASources,
+		},
+
+		// computed
+		schemaCertificate: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the signed certificate in authorized_keys format.",
+		},
+		schemaSerial: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the serial number of the certificate.",
+		},
+		schemaValidBefore: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the RFC3339 timestamp the certificate expires at.",
+		},
+		schemaCAPublicKey: &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "This is the public key of the CA that signed the certificate.",
+		},
+	}
+	for k, v := range extra {
+		s[k] = v
+	}
+	return s
+}
+
+// certificateSigner returns the CA signer, the CA private key is either
+// decrypted through kms or never leaves kms
+func certificateSigner(d *schema.ResourceData, awsClient *aws.Client) (ssh.Signer, error) {
+	if kmsKeyID := d.Get(schemaKmsSigningKeyID).(string); kmsKeyID != "" {
+		signer, err := aws.NewKMSSigner(awsClient.KMS, kmsKeyID)
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	context := stringMap(d.Get(schemaEncryptionContext).(map[string]interface{}))
+	password, err := awsClient.KMS.DecryptBytes(d.Get(schemaEncryptedPassword).(string), context)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := util.DecryptCA(d.Get(schemaEncryptedPrivateKey).(string), password)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewSSHSigner(signer)
+}
+
+// issueCertificate signs the configured public key and sets the certificate attributes
+func issueCertificate(
+	d *schema.ResourceData,
+	meta interface{},
+	certType uint32,
+	validAfter time.Time,
+	permissions ssh.Permissions) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(d.Get(schemaPublicKey).(string)))
+	if err != nil {
+		return errors.Wrap(err, "Could not parse public key")
+	}
+	validityPeriod, err := time.ParseDuration(d.Get(schemaValidityPeriod).(string))
+	if err != nil {
+		return errors.Wrapf(err, "Could not parse %s", schemaValidityPeriod)
+	}
+	principals := listOfStrings(d.Get(schemaPrincipals).([]interface{}))
+	serial, err := util.GenerateSerial()
+	if err != nil {
+		return err
+	}
+	signer, err := certificateSigner(d, awsClient)
+	if err != nil {
+		return err
+	}
+
+	validBefore := validAfter.Add(validityPeriod)
+	certificate := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        certType,
+		KeyId:           d.Get(schemaCertificateKeyID).(string),
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions:     permissions,
+	}
+	signed, err := util.SignCertificate(certificate, signer)
+	if err != nil {
+		return err
+	}
+
+	d.Set(schemaCertificate, signed)                                               // nolint
+	d.Set(schemaSerial, strconv.FormatUint(serial, 10))                            // nolint
+	d.Set(schemaValidAfter, formatTime(validAfter))                                // nolint
+	d.Set(schemaValidBefore, formatTime(validBefore))                              // nolint
+	d.Set(schemaCAPublicKey, string(ssh.MarshalAuthorizedKey(signer.PublicKey()))) // nolint
+	d.SetId(strconv.FormatUint(serial, 10))
+	return nil
+}
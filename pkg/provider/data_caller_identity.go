@@ -0,0 +1,65 @@
+package provider
+
+import (
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	schemaAccountID = "account_id"
+	schemaArn       = "arn"
+	schemaUserID    = "user_id"
+)
+
+// CallerIdentity exposes the aws identity the provider runs as
+func CallerIdentity() *schema.Resource {
+	callerIdentity := newDataCallerIdentity()
+
+	return &schema.Resource{
+		Read: callerIdentity.Read,
+
+		Schema: map[string]*schema.Schema{
+			// computed
+			schemaAccountID: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the aws account id of the provider credentials",
+			},
+			schemaArn: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the arn of the provider credentials",
+			},
+			schemaUserID: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the unique id of the provider credentials",
+			},
+		},
+	}
+}
+
+func newDataCallerIdentity() *dataCallerIdentity {
+	return &dataCallerIdentity{}
+}
+
+type dataCallerIdentity struct{}
+
+func (c *dataCallerIdentity) Read(d *schema.ResourceData, meta interface{}) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+	callerIdentity, err := awsClient.CallerIdentity()
+	if err != nil {
+		return err
+	}
+
+	d.SetId(awssdk.StringValue(callerIdentity.Account))
+	d.Set(schemaAccountID, awssdk.StringValue(callerIdentity.Account)) //nolint
+	d.Set(schemaArn, awssdk.StringValue(callerIdentity.Arn))           //nolint
+	d.Set(schemaUserID, awssdk.StringValue(callerIdentity.UserId))     //nolint
+	return nil
+}
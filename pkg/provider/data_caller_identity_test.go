@@ -0,0 +1,90 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/stretchr/testify/mock"
+)
+
+func callerIdentityOutput() *sts.GetCallerIdentityOutput {
+	return &sts.GetCallerIdentityOutput{
+		Account: awssdk.String("123456789012"),
+		Arn:     awssdk.String("arn:aws:sts::123456789012:assumed-role/bless/terraform"),
+		UserId:  awssdk.String("AROA3XFRBF535PLBIFPI4:terraform"),
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	providers, _, stsMock := getTestProvidersWithSTS()
+	stsMock.On("GetCallerIdentity", mock.Anything).Return(callerIdentityOutput(), nil)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region              = "us-east-1"
+					allowed_account_ids = ["123456789012", "210987654321"]
+				}
+
+				data "bless_caller_identity" "current" {}
+				`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.bless_caller_identity.current", "id", "123456789012"),
+					r.TestCheckResourceAttr("data.bless_caller_identity.current", "account_id", "123456789012"),
+					r.TestCheckResourceAttr("data.bless_caller_identity.current", "arn", "arn:aws:sts::123456789012:assumed-role/bless/terraform"),
+					r.TestCheckResourceAttr("data.bless_caller_identity.current", "user_id", "AROA3XFRBF535PLBIFPI4:terraform"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccountIDs(t *testing.T) {
+	providers, _, stsMock := getTestProvidersWithSTS()
+	stsMock.On("GetCallerIdentity", mock.Anything).Return(callerIdentityOutput(), nil)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region              = "us-east-1"
+					allowed_account_ids = ["210987654321"]
+				}
+
+				data "bless_caller_identity" "current" {}
+				`,
+				ExpectError: regexp.MustCompile("aws account 123456789012 is not one of allowed_account_ids"),
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region                = "us-east-1"
+					forbidden_account_ids = ["123456789012"]
+				}
+
+				data "bless_caller_identity" "current" {}
+				`,
+				ExpectError: regexp.MustCompile("aws account 123456789012 is forbidden by forbidden_account_ids"),
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region              = "us-east-1"
+					allowed_account_ids = ["1234"]
+				}
+
+				data "bless_caller_identity" "current" {}
+				`,
+				ExpectError: regexp.MustCompile("must be a 12 digit aws account id"),
+			},
+		},
+	})
+}
@@ -2,16 +2,34 @@ package provider
 
 import (
 	"crypto/x509"
-	"fmt"
+	"encoding/pem"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 )
 
-const ()
+const (
+	schemaKeySpec           = "key_spec"
+	schemaKeyUsage          = "key_usage"
+	schemaSigningAlgorithms = "signing_algorithms"
+	schemaKeyArn            = "key_arn"
+	schemaPublicKeyPem      = "public_key_pem"
+)
+
+// sshKeySpecs are the kms key specs that can be used as an ssh CA
+var sshKeySpecs = map[string]bool{
+	kms.CustomerMasterKeySpecRsa2048:     true,
+	kms.CustomerMasterKeySpecRsa3072:     true,
+	kms.CustomerMasterKeySpecRsa4096:     true,
+	kms.CustomerMasterKeySpecEccNistP256: true,
+	kms.CustomerMasterKeySpecEccNistP384: true,
+	kms.CustomerMasterKeySpecEccNistP521: true,
+}
 
 func KMSPublicKey() *schema.Resource {
 	kmsPublicKey := newDataKMSPublicKey()
@@ -32,6 +50,42 @@ func KMSPublicKey() *schema.Resource {
 				Computed:    true,
 				Description: "This is the CA public key in openssh format",
 			},
+			schemaPublicKeyPem: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the CA public key as a PEM encoded PKIX public key",
+			},
+			schemaKeyArn: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the arn of the kms key",
+			},
+			schemaKeySpec: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the kms key spec, for example ECC_NIST_P384",
+			},
+			schemaKeyUsage: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the kms key usage, always SIGN_VERIFY",
+			},
+			schemaSigningAlgorithms: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "These are the signing algorithms the kms key supports",
+			},
+			schemaFingerprintSHA256: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the OpenSSH SHA256 fingerprint of the public key",
+			},
+			schemaFingerprintMD5: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the OpenSSH legacy MD5 fingerprint of the public key",
+			},
 		},
 	}
 }
@@ -51,14 +105,21 @@ func (l *dataKMSPublicKey) Read(d *schema.ResourceData, meta interface{}) error
 
 	svc := awsClient.KMS.Svc
 
-	fmt.Printf("nil svc: %#v", svc == nil)
-
 	output, err := svc.GetPublicKey(
 		&kms.GetPublicKeyInput{KeyId: &kmsKeyID},
 	)
 	if err != nil {
 		return errors.Wrap(err, "error getting kms public key")
 	}
+	keyUsage := awssdk.StringValue(output.KeyUsage)
+	if keyUsage != kms.KeyUsageTypeSignVerify {
+		return errors.Errorf("kms key %s has key usage %s, an ssh CA needs %s", kmsKeyID, keyUsage, kms.KeyUsageTypeSignVerify)
+	}
+	keySpec := awssdk.StringValue(output.CustomerMasterKeySpec)
+	if !sshKeySpecs[keySpec] {
+		return errors.Errorf("kms key %s has key spec %s which ssh does not support", kmsKeyID, keySpec)
+	}
+
 	pub, err := x509.ParsePKIXPublicKey(output.PublicKey)
 	if err != nil {
 		return errors.Wrap(err, "could not parse kms public key")
@@ -67,7 +128,25 @@ func (l *dataKMSPublicKey) Read(d *schema.ResourceData, meta interface{}) error
 	if err != nil {
 		return errors.Wrap(err, "could not ssh parse kms public key")
 	}
-	d.SetId(*output.KeyId)                                           //nolint
-	d.Set(schemaPublicKey, string(ssh.MarshalAuthorizedKey(sshPub))) //nolint
+	publicKey := string(ssh.MarshalAuthorizedKey(sshPub))
+	fingerprintSHA256, err := util.FingerprintSHA256(publicKey)
+	if err != nil {
+		return err
+	}
+	fingerprintMD5, err := util.FingerprintMD5(publicKey)
+	if err != nil {
+		return err
+	}
+	publicKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: output.PublicKey})
+
+	d.SetId(*output.KeyId)                                                            //nolint
+	d.Set(schemaPublicKey, publicKey)                                                 //nolint
+	d.Set(schemaPublicKeyPem, string(publicKeyPem))                                   //nolint
+	d.Set(schemaKeyArn, awssdk.StringValue(output.KeyId))                             //nolint
+	d.Set(schemaKeySpec, keySpec)                                                     //nolint
+	d.Set(schemaKeyUsage, keyUsage)                                                   //nolint
+	d.Set(schemaSigningAlgorithms, awssdk.StringValueSlice(output.SigningAlgorithms)) //nolint
+	d.Set(schemaFingerprintSHA256, fingerprintSHA256)                                 //nolint
+	d.Set(schemaFingerprintMD5, fingerprintMD5)                                       //nolint
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
+	"fmt"
 	"regexp"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestKMSPublicKey(t *testing.T) {
@@ -26,9 +28,14 @@ func TestKMSPublicKey(t *testing.T) {
 	derBytes, err := x509.MarshalPKIXPublicKey(priv.Public())
 	r.NoError(err)
 	output := &kms.GetPublicKeyOutput{
-		PublicKey: derBytes,
-		KeyId:     aws.String("key id"),
+		PublicKey:             derBytes,
+		KeyId:                 aws.String("key id"),
+		KeyUsage:              aws.String(kms.KeyUsageTypeSignVerify),
+		CustomerMasterKeySpec: aws.String(kms.CustomerMasterKeySpecEccNistP384),
+		SigningAlgorithms:     aws.StringSlice([]string{kms.SigningAlgorithmSpecEcdsaSha384}),
 	}
+	sshPub, err := ssh.NewPublicKey(priv.Public())
+	r.NoError(err)
 
 	kmsMock.On("GetPublicKey", mock.Anything).Return(output, nil)
 
@@ -49,15 +56,74 @@ func TestKMSPublicKey(t *testing.T) {
 					value = "${data.bless_kms_public_key.bless.public_key}"
 				}
 			`,
-				Check: func(s *terraform.State) error {
-					publicSSHUntyped := s.RootModule().Outputs["public_key"].Value
-					publicSSH, ok := publicSSHUntyped.(string)
-					r.True(ok)
-					r.Regexp(
-						regexp.MustCompile("^ecdsa-sha2-nistp384 "),
-						string(publicSSH))
-					return nil
-				},
+				Check: tf.ComposeTestCheckFunc(
+					func(s *terraform.State) error {
+						publicSSHUntyped := s.RootModule().Outputs["public_key"].Value
+						publicSSH, ok := publicSSHUntyped.(string)
+						r.True(ok)
+						r.Regexp(
+							regexp.MustCompile("^ecdsa-sha2-nistp384 "),
+							string(publicSSH))
+						return nil
+					},
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "key_spec", "ECC_NIST_P384"),
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "key_usage", "SIGN_VERIFY"),
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "key_arn", "key id"),
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "signing_algorithms.0", "ECDSA_SHA_384"),
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "fingerprint_sha256", ssh.FingerprintSHA256(sshPub)),
+					tf.TestCheckResourceAttr("data.bless_kms_public_key.bless", "fingerprint_md5", ssh.FingerprintLegacyMD5(sshPub)),
+					tf.TestMatchResourceAttr("data.bless_kms_public_key.bless", "public_key_pem", regexp.MustCompile("^-----BEGIN PUBLIC KEY-----")),
+				),
+			},
+		},
+	})
+}
+
+func TestKMSPublicKeyUnsupported(t *testing.T) {
+	r := require.New(t)
+	providers, kmsMock := getTestProviders()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	r.NoError(err)
+	derBytes, err := x509.MarshalPKIXPublicKey(priv.Public())
+	r.NoError(err)
+
+	keyOutput := func(keyID string, keyUsage string, keySpec string) {
+		output := &kms.GetPublicKeyOutput{
+			PublicKey:             derBytes,
+			KeyId:                 aws.String(keyID),
+			KeyUsage:              aws.String(keyUsage),
+			CustomerMasterKeySpec: aws.String(keySpec),
+		}
+		kmsMock.On("GetPublicKey", mock.MatchedBy(func(input *kms.GetPublicKeyInput) bool {
+			return aws.StringValue(input.KeyId) == keyID
+		})).Return(output, nil)
+	}
+	keyOutput("encrypt", kms.KeyUsageTypeEncryptDecrypt, kms.CustomerMasterKeySpecSymmetricDefault)
+	keyOutput("secp256k1", kms.KeyUsageTypeSignVerify, kms.CustomerMasterKeySpecEccSecgP256k1)
+
+	config := func(keyID string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		data "bless_kms_public_key" "bless" {
+			kms_key_id = "%s"
+		}
+		`, keyID)
+	}
+
+	tf.Test(t, tf.TestCase{
+		Providers: providers,
+		Steps: []tf.TestStep{
+			tf.TestStep{
+				Config:      config("encrypt"),
+				ExpectError: regexp.MustCompile("has key usage ENCRYPT_DECRYPT"),
+			},
+			tf.TestStep{
+				Config:      config("secp256k1"),
+				ExpectError: regexp.MustCompile("has key spec ECC_SECG_P256K1 which ssh does not support"),
 			},
 		},
 	})
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	schemaKmsauthTo            = "to"
+	schemaKmsauthFrom          = "from"
+	schemaKmsauthUserType      = "user_type"
+	schemaKmsauthTokenVersion  = "token_version"
+	schemaKmsauthTokenLifetime = "token_lifetime"
+	schemaKmsauthToken         = "token"
+	schemaKmsauthUsername      = "username"
+	schemaKmsauthNotBefore     = "not_before"
+	schemaKmsauthNotAfter      = "not_after"
+
+	// kmsauthTimeFormat is the python strftime %Y%m%dT%H%M%SZ kmsauth uses
+	kmsauthTimeFormat = "20060102T150405Z"
+	// kmsauthClockSkew is how far kmsauth backdates tokens
+	kmsauthClockSkew = 3 * time.Minute
+)
+
+// kmsauthPayload is the json kmsauth encrypts
+type kmsauthPayload struct {
+	NotBefore string `json:"not_before"`
+	NotAfter  string `json:"not_after"`
+}
+
+// KmsauthToken generates a kmsauth token to call BLESS with
+func KmsauthToken() *schema.Resource {
+	kmsauthToken := newDataKmsauthToken()
+
+	return &schema.Resource{
+		Read: kmsauthToken.Read,
+
+		Schema: map[string]*schema.Schema{
+			schemaKmsKeyID: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The kmsauth kms key",
+			},
+			schemaKmsauthTo: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The service the token is for, the BLESS kmsauth_serviceid",
+			},
+			schemaKmsauthFrom: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The user or service the token is from",
+			},
+			schemaKmsauthUserType: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "user",
+				Description:  "The type of the from principal, user or service. Only used by version 2 tokens",
+				ValidateFunc: validation.StringInSlice([]string{"user", "service"}, false),
+			},
+			schemaKmsauthTokenVersion: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2,
+				Description:  "The kmsauth token version, 1 or 2",
+				ValidateFunc: validation.IntInSlice([]int{1, 2}),
+			},
+			schemaKmsauthTokenLifetime: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10m",
+				Description:  "How long the token is valid for, a duration such as 10m",
+				ValidateFunc: validateDuration,
+			},
+
+			// computed
+			schemaKmsauthToken: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "This is the base64 encoded kmsauth token",
+			},
+			schemaKmsauthUsername: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the kmsauth username of the token, 2/<user_type>/<from> for version 2 tokens",
+			},
+			schemaKmsauthNotBefore: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the RFC3339 timestamp the token is valid from",
+			},
+			schemaKmsauthNotAfter: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the RFC3339 timestamp the token expires at",
+			},
+		},
+	}
+}
+
+func newDataKmsauthToken() *dataKmsauthToken {
+	return &dataKmsauthToken{}
+}
+
+type dataKmsauthToken struct{}
+
+func (k *dataKmsauthToken) Read(d *schema.ResourceData, meta interface{}) error {
+	awsClient, ok := meta.(*aws.Client)
+	if !ok {
+		return errors.New("meta is not of type *aws.Client")
+	}
+	lifetime, err := time.ParseDuration(d.Get(schemaKmsauthTokenLifetime).(string))
+	if err != nil {
+		return errors.Wrapf(err, "Could not parse %s", schemaKmsauthTokenLifetime)
+	}
+
+	from := d.Get(schemaKmsauthFrom).(string)
+	context := map[string]string{
+		schemaKmsauthTo:   d.Get(schemaKmsauthTo).(string),
+		schemaKmsauthFrom: from,
+	}
+	username := from
+	if version := d.Get(schemaKmsauthTokenVersion).(int); version == 2 {
+		userType := d.Get(schemaKmsauthUserType).(string)
+		context[schemaKmsauthUserType] = userType
+		username = strconv.Itoa(version) + "/" + userType + "/" + from
+	}
+
+	now := time.Now().UTC()
+	notBefore := now.Add(-kmsauthClockSkew)
+	notAfter := now.Add(lifetime)
+	payload, err := json.Marshal(kmsauthPayload{
+		NotBefore: notBefore.Format(kmsauthTimeFormat),
+		NotAfter:  notAfter.Format(kmsauthTimeFormat),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Could not marshal kmsauth payload")
+	}
+	token, err := awsClient.KMS.EncryptBytes(payload, d.Get(schemaKmsKeyID).(string), context)
+	if err != nil {
+		return errors.Wrap(err, "Could not generate kmsauth token")
+	}
+
+	d.SetId(util.HashForState(token))
+	d.Set(schemaKmsauthToken, token)                     //nolint
+	d.Set(schemaKmsauthUsername, username)               //nolint
+	d.Set(schemaKmsauthNotBefore, formatTime(notBefore)) //nolint
+	d.Set(schemaKmsauthNotAfter, formatTime(notAfter))   //nolint
+	return nil
+}
@@ -0,0 +1,134 @@
+package provider_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/stretchr/testify/mock"
+)
+
+// kmsauthEncryptInput matches an encrypt of a kmsauth payload valid for lifetime
+func kmsauthEncryptInput(context map[string]string, lifetime time.Duration) interface{} {
+	return mock.MatchedBy(func(input *kms.EncryptInput) bool {
+		if awssdk.StringValue(input.KeyId) != "kmsauth-key" {
+			return false
+		}
+		if len(input.EncryptionContext) != len(context) {
+			return false
+		}
+		for k, v := range context {
+			if awssdk.StringValue(input.EncryptionContext[k]) != v {
+				return false
+			}
+		}
+
+		payload := map[string]string{}
+		err := json.Unmarshal(input.Plaintext, &payload)
+		if err != nil || len(payload) != 2 {
+			return false
+		}
+		notBefore, err := time.Parse("20060102T150405Z", payload["not_before"])
+		if err != nil {
+			return false
+		}
+		notAfter, err := time.Parse("20060102T150405Z", payload["not_after"])
+		if err != nil {
+			return false
+		}
+		return notAfter.Sub(notBefore) == lifetime+3*time.Minute
+	})
+}
+
+func TestKmsauthToken(t *testing.T) {
+	providers, kmsMock := getTestProviders()
+
+	output := &kms.EncryptOutput{
+		CiphertextBlob: []byte("kmsauth token"),
+	}
+	token := base64.StdEncoding.EncodeToString(output.CiphertextBlob)
+	kmsMock.On("Encrypt", kmsauthEncryptInput(map[string]string{
+		"to":        "bless-production",
+		"from":      "deploy",
+		"user_type": "service",
+	}, 10*time.Minute)).Return(output, nil)
+	kmsMock.On("Encrypt", kmsauthEncryptInput(map[string]string{
+		"to":   "bless-production",
+		"from": "alice",
+	}, time.Hour)).Return(output, nil)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_kmsauth_token" "token" {
+					kms_key_id = "kmsauth-key"
+					to         = "bless-production"
+					from       = "deploy"
+					user_type  = "service"
+				}
+				`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.bless_kmsauth_token.token", "token", token),
+					r.TestCheckResourceAttr("data.bless_kmsauth_token.token", "username", "2/service/deploy"),
+					r.TestCheckResourceAttrSet("data.bless_kmsauth_token.token", "not_before"),
+					r.TestCheckResourceAttrSet("data.bless_kmsauth_token.token", "not_after"),
+				),
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_kmsauth_token" "token" {
+					kms_key_id     = "kmsauth-key"
+					to             = "bless-production"
+					from           = "alice"
+					token_version  = 1
+					token_lifetime = "1h"
+				}
+				`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.bless_kmsauth_token.token", "token", token),
+					r.TestCheckResourceAttr("data.bless_kmsauth_token.token", "username", "alice"),
+				),
+			},
+		},
+	})
+}
+
+func TestKmsauthTokenValidation(t *testing.T) {
+	providers, _ := getTestProviders()
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_kmsauth_token" "token" {
+					kms_key_id    = "kmsauth-key"
+					to            = "bless-production"
+					from          = "alice"
+					token_version = 3
+				}
+				`,
+				ExpectError: regexp.MustCompile(`expected token_version to be one of \[1 2\]`),
+			},
+		},
+	})
+}
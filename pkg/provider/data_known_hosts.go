@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	schemaCAPublicKeys = "ca_public_keys"
+	schemaHostPatterns = "host_patterns"
+	schemaRevokedKeys  = "revoked_keys"
+	schemaKnownHosts   = "known_hosts"
+
+	knownHostsMarkerCertAuthority = "@cert-authority"
+	knownHostsMarkerRevoked       = "@revoked"
+)
+
+// hostPatternRegexp matches a single known_hosts host pattern
+var hostPatternRegexp = regexp.MustCompile(`^[^\s,#]+$`)
+
+// KnownHosts renders a known_hosts file trusting host CAs
+func KnownHosts() *schema.Resource {
+	knownHosts := newDataKnownHosts()
+
+	return &schema.Resource{
+		Read: knownHosts.Read,
+
+		Schema: map[string]*schema.Schema{
+			schemaCAPublicKeys: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The host CA public keys in authorized_keys format",
+			},
+			schemaHostPatterns: {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(hostPatternRegexp, "host patterns can not have whitespace, commas or #"),
+				},
+				Description: "The host patterns the CAs are trusted for, for example *.corp.example",
+			},
+			schemaRevokedKeys: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Revoked host or CA public keys in authorized_keys format",
+			},
+
+			// computed
+			schemaKnownHosts: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the known_hosts file body",
+			},
+		},
+	}
+}
+
+func newDataKnownHosts() *dataKnownHosts {
+	return &dataKnownHosts{}
+}
+
+type dataKnownHosts struct{}
+
+func (k *dataKnownHosts) Read(d *schema.ResourceData, meta interface{}) error {
+	hostPatterns := strings.Join(listOfStrings(d.Get(schemaHostPatterns).([]interface{})), ",")
+
+	caKeys, err := util.ParseAuthorizedKeys(listOfStrings(d.Get(schemaCAPublicKeys).([]interface{})))
+	if err != nil {
+		return errors.Wrapf(err, "Invalid %s", schemaCAPublicKeys)
+	}
+	revokedKeys, err := util.ParseAuthorizedKeys(listOfStrings(d.Get(schemaRevokedKeys).([]interface{})))
+	if err != nil {
+		return errors.Wrapf(err, "Invalid %s", schemaRevokedKeys)
+	}
+
+	var knownHosts strings.Builder
+	for _, key := range caKeys {
+		knownHosts.WriteString(key.CommentLine() + "\n")
+		knownHosts.WriteString(knownHostsMarkerCertAuthority + " " + hostPatterns + " " + key.Line() + "\n")
+	}
+	for _, key := range revokedKeys {
+		knownHosts.WriteString(key.CommentLine() + "\n")
+		knownHosts.WriteString(knownHostsMarkerRevoked + " " + hostPatterns + " " + key.Line() + "\n")
+	}
+
+	d.SetId(util.HashForState(knownHosts.String()))
+	d.Set(schemaKnownHosts, knownHosts.String()) //nolint
+	return nil
+}
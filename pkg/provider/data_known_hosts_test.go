@@ -0,0 +1,88 @@
+package provider_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testAuthorizedKey generates an ed25519 public key and returns it in
+// authorized_keys format without a comment along with its fingerprint
+func testAuthorizedKey(t *testing.T) (string, string) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), ssh.FingerprintSHA256(sshPub)
+}
+
+func TestKnownHosts(t *testing.T) {
+	providers, _ := getTestProviders()
+
+	oldCA, oldFingerprint := testAuthorizedKey(t)
+	newCA, newFingerprint := testAuthorizedKey(t)
+	revoked, revokedFingerprint := testAuthorizedKey(t)
+
+	caLines := map[string]string{
+		oldFingerprint: fmt.Sprintf("# %s old\n@cert-authority *.corp.example,10.0.* %s\n", oldFingerprint, oldCA),
+		newFingerprint: fmt.Sprintf("# %s\n@cert-authority *.corp.example,10.0.* %s\n", newFingerprint, newCA),
+	}
+	fingerprints := []string{oldFingerprint, newFingerprint}
+	sort.Strings(fingerprints)
+	expected := caLines[fingerprints[0]] + caLines[fingerprints[1]] +
+		fmt.Sprintf("# %s\n@revoked *.corp.example,10.0.* %s\n", revokedFingerprint, revoked)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_known_hosts" "corp" {
+					ca_public_keys = ["%s old", "%s", "%s duplicate"]
+					host_patterns  = ["*.corp.example", "10.0.*"]
+					revoked_keys   = ["%s"]
+				}
+			`, oldCA, newCA, oldCA, revoked),
+				Check: r.TestCheckResourceAttr("data.bless_known_hosts.corp", "known_hosts", expected),
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_known_hosts" "corp" {
+					ca_public_keys = ["ssh-ed25519 not-base64"]
+					host_patterns  = ["*.corp.example"]
+				}
+			`,
+				ExpectError: regexp.MustCompile("Invalid ca_public_keys"),
+			},
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_known_hosts" "corp" {
+					ca_public_keys = ["%s"]
+					host_patterns  = ["a.corp.example b.corp.example"]
+				}
+			`, newCA),
+				ExpectError: regexp.MustCompile("host patterns can not have whitespace"),
+			},
+		},
+	})
+}
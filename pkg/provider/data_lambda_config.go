@@ -0,0 +1,299 @@
+package provider
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	schemaCertificateValidityBeforeSeconds       = "certificate_validity_before_seconds"
+	schemaCertificateValidityAfterSeconds        = "certificate_validity_after_seconds"
+	schemaServerCertificateValidityBeforeSeconds = "server_certificate_validity_before_seconds"
+	schemaServerCertificateValidityAfterSeconds  = "server_certificate_validity_after_seconds"
+	schemaEntropyMinimumBits                     = "entropy_minimum_bits"
+	schemaRandomSeedBytes                        = "random_seed_bytes"
+	schemaLoggingLevel                           = "logging_level"
+	schemaCertificateExtensions                  = "certificate_extensions"
+	schemaUsernameValidation                     = "username_validation"
+	schemaRemoteUsernamesValidation              = "remote_usernames_validation"
+	schemaRemoteUsernamesBlacklist               = "remote_usernames_blacklist"
+	schemaHostnameValidation                     = "hostname_validation"
+
+	schemaUseKmsauth                                     = "use_kmsauth"
+	schemaKmsauthKeyIDs                                  = "kmsauth_key_ids"
+	schemaKmsauthServiceID                               = "kmsauth_serviceid"
+	schemaKmsauthRemoteUsernamesAllowed                  = "kmsauth_remote_usernames_allowed"
+	schemaKmsauthValidateRemoteUsernamesAgainstIAMGroups = "kmsauth_validate_remote_usernames_against_iam_groups"
+	schemaKmsauthIAMGroupNameFormat                      = "kmsauth_iam_group_name_format"
+
+	schemaLambdaConfig = "config"
+
+	// the BLESS config sections
+	lambdaConfigSectionOptions = "Bless Options"
+	lambdaConfigSectionCA      = "Bless CA"
+	lambdaConfigSectionKmsauth = "KMS Auth"
+)
+
+// usernameValidations are the BLESS username validation modes
+var usernameValidations = []string{"useradd", "debian", "relaxed", "principal", "email", "disabled"}
+
+// LambdaConfig renders the BLESS lambda deploy config
+func LambdaConfig() *schema.Resource {
+	lambdaConfig := newDataLambdaConfig()
+
+	return &schema.Resource{
+		Read: lambdaConfig.Read,
+
+		Schema: map[string]*schema.Schema{
+			// Bless CA
+			schemaEncryptedPrivateKey: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The encrypted_ca of a CA resource, it must be in the pem_rfc1423 private_key_format",
+			},
+			schemaEncryptedPasswords: {
+				Type:         schema.TypeMap,
+				Required:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Description:  "The kms encrypted CA passwords keyed by the regions the lambda is deployed to",
+				ValidateFunc: validateRegionKeys,
+			},
+
+			// Bless Options
+			schemaCertificateValidityBeforeSeconds: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      120,
+				Description:  "How many seconds before issuance user certificates are valid",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			schemaCertificateValidityAfterSeconds: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      120,
+				Description:  "How many seconds after issuance user certificates are valid",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			schemaServerCertificateValidityBeforeSeconds: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      120,
+				Description:  "How many seconds before issuance host certificates are valid",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			schemaServerCertificateValidityAfterSeconds: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      31536000,
+				Description:  "How many seconds after issuance host certificates are valid",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			schemaEntropyMinimumBits: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2048,
+				Description:  "The entropy pool size in bits below which the lambda seeds it from kms",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			schemaRandomSeedBytes: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      256,
+				Description:  "How many random bytes to fetch from kms to seed the entropy pool",
+				ValidateFunc: validation.IntBetween(1, 1024),
+			},
+			schemaLoggingLevel: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "INFO",
+				Description:  "The lambda logging level",
+				ValidateFunc: validation.StringInSlice([]string{"DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}, false),
+			},
+			schemaCertificateExtensions: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The extensions of user certificates, defaults to the BLESS defaults",
+			},
+			schemaUsernameValidation: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "useradd",
+				Description:  "How bastion usernames are validated",
+				ValidateFunc: validation.StringInSlice(usernameValidations, false),
+			},
+			schemaRemoteUsernamesValidation: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "principal",
+				Description:  "How remote usernames are validated",
+				ValidateFunc: validation.StringInSlice(usernameValidations, false),
+			},
+			schemaRemoteUsernamesBlacklist: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "A regex of remote usernames that are always rejected, for example root|admin.*",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			schemaHostnameValidation: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "url",
+				Description:  "How host certificate hostnames are validated",
+				ValidateFunc: validation.StringInSlice([]string{"url", "disabled"}, false),
+			},
+
+			// KMS Auth
+			schemaUseKmsauth: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Require a kmsauth token so certificate usernames match the aws user",
+			},
+			schemaKmsauthKeyIDs: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The arns of the kmsauth kms keys",
+			},
+			schemaKmsauthServiceID: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The kmsauth service id, the to context of kmsauth tokens",
+			},
+			schemaKmsauthRemoteUsernamesAllowed: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The remote usernames any kmsauth user can request",
+			},
+			schemaKmsauthValidateRemoteUsernamesAgainstIAMGroups: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Validate remote usernames against the iam groups of the kmsauth user",
+			},
+			schemaKmsauthIAMGroupNameFormat: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "ssh-{}",
+				Description: "The iam group name of a remote username, {} is replaced by the username",
+			},
+
+			// computed
+			schemaLambdaConfig: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the bless_deploy.cfg file body",
+			},
+		},
+	}
+}
+
+func newDataLambdaConfig() *dataLambdaConfig {
+	return &dataLambdaConfig{}
+}
+
+type dataLambdaConfig struct{}
+
+func (l *dataLambdaConfig) Read(d *schema.ResourceData, meta interface{}) error {
+	options := util.INISection{Name: lambdaConfigSectionOptions}
+	for _, key := range []string{
+		schemaCertificateValidityBeforeSeconds,
+		schemaCertificateValidityAfterSeconds,
+		schemaServerCertificateValidityBeforeSeconds,
+		schemaServerCertificateValidityAfterSeconds,
+		schemaEntropyMinimumBits,
+		schemaRandomSeedBytes,
+	} {
+		options.Options = append(options.Options, util.INIOption{Key: key, Value: strconv.Itoa(d.Get(key).(int))})
+	}
+	options.Options = append(options.Options,
+		util.INIOption{Key: schemaLoggingLevel, Value: d.Get(schemaLoggingLevel).(string)},
+		util.INIOption{Key: schemaUsernameValidation, Value: d.Get(schemaUsernameValidation).(string)},
+		util.INIOption{Key: schemaRemoteUsernamesValidation, Value: d.Get(schemaRemoteUsernamesValidation).(string)},
+		util.INIOption{Key: schemaHostnameValidation, Value: d.Get(schemaHostnameValidation).(string)},
+	)
+	if extensions := listOfStrings(d.Get(schemaCertificateExtensions).([]interface{})); len(extensions) > 0 {
+		options.Options = append(options.Options, util.INIOption{Key: schemaCertificateExtensions, Value: strings.Join(extensions, ",")})
+	}
+	if blacklist := d.Get(schemaRemoteUsernamesBlacklist).(string); blacklist != "" {
+		options.Options = append(options.Options, util.INIOption{Key: schemaRemoteUsernamesBlacklist, Value: blacklist})
+	}
+
+	// BLESS looks up the password of the region it runs in
+	ca := util.INISection{Name: lambdaConfigSectionCA}
+	encryptedPasswords := stringMap(d.Get(schemaEncryptedPasswords).(map[string]interface{}))
+	regions := []string{}
+	for region := range encryptedPasswords {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		ca.Options = append(ca.Options, util.INIOption{Key: region + "_password", Value: encryptedPasswords[region]})
+	}
+	encryptedCA := d.Get(schemaEncryptedPrivateKey).(string)
+	format, err := util.DetectPrivateKeyFormat(encryptedCA)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid %s", schemaEncryptedPrivateKey)
+	}
+	// BLESS only loads the legacy encrypted PEM keys
+	if format != util.PrivateKeyFormatPEMRFC1423 {
+		return errors.Errorf("BLESS only loads %s CA private keys, %s is %s", util.PrivateKeyFormatPEMRFC1423, schemaEncryptedPrivateKey, format)
+	}
+	ca.Options = append(ca.Options, util.INIOption{Key: "ca_private_key", Value: encryptedCA})
+	compression, err := util.DetectCACompression(encryptedCA)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid %s", schemaEncryptedPrivateKey)
+	}
+	if compression != util.CompressionNone {
+		ca.Options = append(ca.Options, util.INIOption{Key: "ca_private_key_compression", Value: string(compression)})
+	}
+
+	useKmsauth := d.Get(schemaUseKmsauth).(bool)
+	kmsauthKeyIDs := listOfStrings(d.Get(schemaKmsauthKeyIDs).([]interface{}))
+	kmsauthServiceID := d.Get(schemaKmsauthServiceID).(string)
+	if useKmsauth && (len(kmsauthKeyIDs) == 0 || kmsauthServiceID == "") {
+		return errors.Errorf("%s requires %s and %s", schemaUseKmsauth, schemaKmsauthKeyIDs, schemaKmsauthServiceID)
+	}
+	kmsauth := util.INISection{Name: lambdaConfigSectionKmsauth}
+	kmsauth.Options = append(kmsauth.Options, util.INIOption{Key: schemaUseKmsauth, Value: pythonBool(useKmsauth)})
+	if len(kmsauthKeyIDs) > 0 {
+		kmsauth.Options = append(kmsauth.Options, util.INIOption{Key: "kmsauth_key_id", Value: strings.Join(kmsauthKeyIDs, ",")})
+	}
+	if kmsauthServiceID != "" {
+		kmsauth.Options = append(kmsauth.Options, util.INIOption{Key: schemaKmsauthServiceID, Value: kmsauthServiceID})
+	}
+	if allowed := listOfStrings(d.Get(schemaKmsauthRemoteUsernamesAllowed).([]interface{})); len(allowed) > 0 {
+		kmsauth.Options = append(kmsauth.Options, util.INIOption{Key: schemaKmsauthRemoteUsernamesAllowed, Value: strings.Join(allowed, ",")})
+	}
+	kmsauth.Options = append(kmsauth.Options,
+		util.INIOption{
+			Key:   schemaKmsauthValidateRemoteUsernamesAgainstIAMGroups,
+			Value: pythonBool(d.Get(schemaKmsauthValidateRemoteUsernamesAgainstIAMGroups).(bool)),
+		},
+		util.INIOption{Key: schemaKmsauthIAMGroupNameFormat, Value: d.Get(schemaKmsauthIAMGroupNameFormat).(string)},
+	)
+
+	config, err := util.RenderINI([]util.INISection{options, ca, kmsauth})
+	if err != nil {
+		return err
+	}
+	d.SetId(util.HashForState(config))
+	d.Set(schemaLambdaConfig, config) //nolint
+	return nil
+}
+
+// pythonBool formats a bool the way python's configparser reads it
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
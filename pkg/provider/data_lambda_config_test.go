@@ -0,0 +1,133 @@
+package provider_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLambdaConfig(t *testing.T) {
+	providers, _ := getTestProviders()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca, err := util.NewCA(privateKey, privateKey.Public(), 64, util.PrivateKeyFormatPEMRFC1423, util.CompressionZlib)
+	require.NoError(t, err)
+	encryptedCA := ca.B64EncryptedPrivateKey
+	pkcs8CA, err := util.NewCA(privateKey, privateKey.Public(), 64, util.PrivateKeyFormatPKCS8PBES2, util.CompressionNone)
+	require.NoError(t, err)
+
+	expected := fmt.Sprintf(`[Bless Options]
+certificate_validity_before_seconds = 120
+certificate_validity_after_seconds = 3600
+server_certificate_validity_before_seconds = 120
+server_certificate_validity_after_seconds = 31536000
+entropy_minimum_bits = 2048
+random_seed_bytes = 256
+logging_level = DEBUG
+username_validation = useradd
+remote_usernames_validation = principal
+hostname_validation = url
+certificate_extensions = permit-pty,permit-agent-forwarding
+remote_usernames_blacklist = root|admin.*
+
+[Bless CA]
+us-east-1_password = east-password
+us-west-2_password = west-password
+ca_private_key = %s
+ca_private_key_compression = zlib
+
+[KMS Auth]
+use_kmsauth = True
+kmsauth_key_id = arn:aws:kms:us-east-1:123456789012:key/east,arn:aws:kms:us-west-2:123456789012:key/west
+kmsauth_serviceid = bless-production
+kmsauth_remote_usernames_allowed = ubuntu,deploy
+kmsauth_validate_remote_usernames_against_iam_groups = False
+kmsauth_iam_group_name_format = ssh-{}
+`, encryptedCA)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_lambda_config" "bless" {
+					encrypted_ca        = "%s"
+					encrypted_passwords = {
+						"us-west-2" = "west-password"
+						"us-east-1" = "east-password"
+					}
+
+					certificate_validity_after_seconds = 3600
+					logging_level                      = "DEBUG"
+					certificate_extensions             = ["permit-pty", "permit-agent-forwarding"]
+					remote_usernames_blacklist         = "root|admin.*"
+
+					use_kmsauth                      = true
+					kmsauth_key_ids                  = [
+						"arn:aws:kms:us-east-1:123456789012:key/east",
+						"arn:aws:kms:us-west-2:123456789012:key/west",
+					]
+					kmsauth_serviceid                = "bless-production"
+					kmsauth_remote_usernames_allowed = ["ubuntu", "deploy"]
+				}
+			`, encryptedCA),
+				Check: r.TestCheckResourceAttr("data.bless_lambda_config.bless", "config", expected),
+			},
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_lambda_config" "bless" {
+					encrypted_ca        = "%s"
+					encrypted_passwords = { "us-east-1" = "east-password" }
+					use_kmsauth         = true
+				}
+			`, encryptedCA),
+				ExpectError: regexp.MustCompile("use_kmsauth requires kmsauth_key_ids and kmsauth_serviceid"),
+			},
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_lambda_config" "bless" {
+					encrypted_ca        = "%s"
+					encrypted_passwords = { "us-east-1" = "east-password" }
+				}
+			`, pkcs8CA.B64EncryptedPrivateKey),
+				ExpectError: regexp.MustCompile("BLESS only loads pem_rfc1423 CA private keys, encrypted_ca is pkcs8_pbes2"),
+			},
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_lambda_config" "bless" {
+					encrypted_ca        = "%s"
+					encrypted_passwords = {
+						"us-east-1" = "east-password"
+						"dr"        = "dr-password"
+					}
+				}
+			`, encryptedCA),
+				ExpectError: regexp.MustCompile("expected the keys of encrypted_passwords to be aws regions, got dr"),
+			},
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	schemaMinRSABits        = "min_rsa_bits"
+	schemaAllowedKeyTypes   = "allowed_key_types"
+	schemaTrustedUserCAKeys = "trusted_user_ca_keys"
+)
+
+// trustedCAKeyTypes are the key types sshd can trust as a user CA, ssh-dss is
+// never allowed
+var trustedCAKeyTypes = []string{
+	ssh.KeyAlgoRSA,
+	ssh.KeyAlgoECDSA256,
+	ssh.KeyAlgoECDSA384,
+	ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoED25519,
+	ssh.KeyAlgoSKECDSA256,
+	ssh.KeyAlgoSKED25519,
+}
+
+// TrustedUserCAKeys renders an sshd TrustedUserCAKeys file
+func TrustedUserCAKeys() *schema.Resource {
+	trustedUserCAKeys := newDataTrustedUserCAKeys()
+
+	return &schema.Resource{
+		Read: trustedUserCAKeys.Read,
+
+		Schema: map[string]*schema.Schema{
+			schemaCAPublicKeys: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The user CA public keys in authorized_keys format, for example the old and new CA during a rotation",
+			},
+			schemaMinRSABits: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      minKeyBits,
+				Description:  "The minimum size in bits of rsa CA keys",
+				ValidateFunc: validation.IntAtLeast(minKeyBits),
+			},
+			schemaAllowedKeyTypes: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(trustedCAKeyTypes, false),
+				},
+				Description: "The CA key types to allow, defaults to every type but ssh-dss",
+			},
+
+			// computed
+			schemaTrustedUserCAKeys: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the TrustedUserCAKeys file body",
+			},
+		},
+	}
+}
+
+func newDataTrustedUserCAKeys() *dataTrustedUserCAKeys {
+	return &dataTrustedUserCAKeys{}
+}
+
+type dataTrustedUserCAKeys struct{}
+
+func (t *dataTrustedUserCAKeys) Read(d *schema.ResourceData, meta interface{}) error {
+	caKeys, err := util.ParseAuthorizedKeys(listOfStrings(d.Get(schemaCAPublicKeys).([]interface{})))
+	if err != nil {
+		return errors.Wrapf(err, "Invalid %s", schemaCAPublicKeys)
+	}
+
+	allowedKeyTypes := listOfStrings(d.Get(schemaAllowedKeyTypes).([]interface{}))
+	if len(allowedKeyTypes) == 0 {
+		allowedKeyTypes = trustedCAKeyTypes
+	}
+	minRSABits := d.Get(schemaMinRSABits).(int)
+
+	var trustedUserCAKeys strings.Builder
+	for _, key := range caKeys {
+		err = checkCAKeyPolicy(key, allowedKeyTypes, minRSABits)
+		if err != nil {
+			return err
+		}
+		trustedUserCAKeys.WriteString(key.CommentLine() + "\n")
+		trustedUserCAKeys.WriteString(key.Line() + "\n")
+	}
+
+	d.SetId(util.HashForState(trustedUserCAKeys.String()))
+	d.Set(schemaTrustedUserCAKeys, trustedUserCAKeys.String()) //nolint
+	return nil
+}
+
+// checkCAKeyPolicy rejects CA keys weaker than the configured policy
+func checkCAKeyPolicy(key util.AuthorizedKey, allowedKeyTypes []string, minRSABits int) error {
+	keyType := key.PublicKey.Type()
+	allowed := false
+	for _, allowedKeyType := range allowedKeyTypes {
+		allowed = allowed || keyType == allowedKeyType
+	}
+	if !allowed {
+		return errors.Errorf("CA key %s has key type %s which is not allowed", key.FingerprintSHA256, keyType)
+	}
+	if keyType == ssh.KeyAlgoRSA && key.RSABits() < minRSABits {
+		return errors.Errorf("CA key %s has %d bits, %s is %d", key.FingerprintSHA256, key.RSABits(), schemaMinRSABits, minRSABits)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package provider_test
+
+import (
+	"crypto/dsa" // nolint
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestTrustedUserCAKeys(t *testing.T) {
+	req := require.New(t)
+	providers, _ := getTestProviders()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	req.NoError(err)
+	rsaPub, err := ssh.NewPublicKey(rsaKey.Public())
+	req.NoError(err)
+	oldCA := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(rsaPub)))
+	oldFingerprint := ssh.FingerprintSHA256(rsaPub)
+	newCA, newFingerprint := testAuthorizedKey(t)
+
+	dsaKey := &dsa.PrivateKey{}
+	req.NoError(dsa.GenerateParameters(&dsaKey.Parameters, rand.Reader, dsa.L1024N160)) // nolint
+	req.NoError(dsa.GenerateKey(dsaKey, rand.Reader))                                   // nolint
+	dsaPub, err := ssh.NewPublicKey(&dsaKey.PublicKey)
+	req.NoError(err)
+	dsaCA := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(dsaPub)))
+
+	lines := map[string]string{
+		oldFingerprint: fmt.Sprintf("# %s old-ca\n%s\n", oldFingerprint, oldCA),
+		newFingerprint: fmt.Sprintf("# %s new-ca\n%s\n", newFingerprint, newCA),
+	}
+	fingerprints := []string{oldFingerprint, newFingerprint}
+	sort.Strings(fingerprints)
+	expected := lines[fingerprints[0]] + lines[fingerprints[1]]
+
+	config := func(keys string, policy string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		data "bless_trusted_user_ca_keys" "bless" {
+			ca_public_keys = [%s]
+			%s
+		}
+		`, keys, policy)
+	}
+	rotation := fmt.Sprintf(`"%s new-ca", "%s old-ca", "%s new-ca"`, newCA, oldCA, newCA)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: config(rotation, ""),
+				Check:  r.TestCheckResourceAttr("data.bless_trusted_user_ca_keys.bless", "trusted_user_ca_keys", expected),
+			},
+			r.TestStep{
+				Config:      config(rotation, "min_rsa_bits = 4096"),
+				ExpectError: regexp.MustCompile("has 2048 bits, min_rsa_bits is 4096"),
+			},
+			r.TestStep{
+				Config:      config(rotation, `allowed_key_types = ["ssh-ed25519"]`),
+				ExpectError: regexp.MustCompile("has key type ssh-rsa which is not allowed"),
+			},
+			r.TestStep{
+				Config:      config(fmt.Sprintf(`"%s"`, dsaCA), ""),
+				ExpectError: regexp.MustCompile("has key type ssh-dss which is not allowed"),
+			},
+			r.TestStep{
+				Config:      config(`"ssh-rsa AAAA"`, ""),
+				ExpectError: regexp.MustCompile("Invalid ca_public_keys"),
+			},
+		},
+	})
+}
@@ -1,8 +1,11 @@
 package provider
 
 import (
+	"time"
+
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 // Provider is a provider
@@ -26,20 +29,234 @@ func Provider() *schema.Provider {
 			"role_arn": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"profile"},
+				ConflictsWith: []string{"profile", "assume_role"},
+			},
+			"assume_role": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"role_arn"},
+				Description:   "The role to assume, a role_arn with more options",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role_arn": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The arn of the role to assume",
+						},
+						"session_name": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The session name, shows up in CloudTrail",
+							ValidateFunc: validateRoleSessionName,
+						},
+						"external_id": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The external id the role trust policy requires",
+							ValidateFunc: validation.StringLenBetween(2, 1224),
+						},
+						"policy": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "A json session policy further restricting the role",
+							ValidateFunc: validation.StringIsJSON,
+						},
+						"duration": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "How long the role session lasts, a duration from 15m to 12h such as 1h",
+							ValidateFunc: validateDurationBetween(15*time.Minute, 12*time.Hour),
+						},
+						"mfa_serial": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The serial number or arn of the mfa device the role requires",
+							RequiredWith: []string{"assume_role.0.mfa_token"},
+						},
+						"mfa_token": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The current code of the mfa_serial device, it is sent again when the session expires and sts rejects a reused code, so duration has to outlast the apply",
+							RequiredWith: []string{"assume_role.0.mfa_serial"},
+						},
+					},
+				},
+			},
+			"assume_role_with_web_identity": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"access_key"},
+				Description:   "The role to assume with an OIDC web identity token, for example from GitHub Actions",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role_arn": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The arn of the role to assume",
+						},
+						"session_name": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The session name, shows up in CloudTrail",
+							ValidateFunc: validateRoleSessionName,
+						},
+						"web_identity_token_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The file the web identity token is read from, it is re-read when the credentials are refreshed",
+							ExactlyOneOf: []string{
+								"assume_role_with_web_identity.0.web_identity_token_file",
+								"assume_role_with_web_identity.0.web_identity_token",
+							},
+						},
+						"web_identity_token": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The web identity token",
+							ExactlyOneOf: []string{
+								"assume_role_with_web_identity.0.web_identity_token_file",
+								"assume_role_with_web_identity.0.web_identity_token",
+							},
+						},
+					},
+				},
+			},
+			"access_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The aws access key, instead of the default credential chain",
+				RequiredWith: []string{"secret_key"},
+			},
+			"secret_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "The aws secret key",
+				RequiredWith: []string{"access_key"},
+			},
+			"token": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "The aws session token of temporary access_key credentials",
+				RequiredWith: []string{"access_key"},
+			},
+			"shared_credentials_files": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Shared credentials files to read profile from, later files take precedence",
+			},
+			"allowed_account_ids": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString, ValidateFunc: validateAccountID},
+				ConflictsWith: []string{"forbidden_account_ids"},
+				Description:   "The only aws accounts the provider may use",
+			},
+			"forbidden_account_ids": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString, ValidateFunc: validateAccountID},
+				ConflictsWith: []string{"allowed_account_ids"},
+				Description:   "The aws accounts the provider must not use",
+			},
+			"endpoints": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Custom service endpoints of the provider region, for example LocalStack or VPC interface endpoints",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"kms": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The kms endpoint url",
+							ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+						},
+						"sts": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The sts endpoint url",
+							ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+						},
+					},
+				},
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				Description:  "How many times a throttled or failed aws request is retried, 0 disables retries",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_min_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "200ms",
+				Description:  "The initial retry backoff, it doubles with every retry",
+				ValidateFunc: validateDuration,
+			},
+			"retry_max_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				Description:  "The longest retry backoff",
+				ValidateFunc: validateDuration,
+			},
+			"max_requests_per_second": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      20,
+				Description:  "Limits the aws requests of all resources using this provider, 0 disables the limit",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip validating the credentials with sts GetCallerIdentity, for runners that only reach kms. allowed_account_ids and forbidden_account_ids always call sts",
+			},
+			"skip_region_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip validating the region name, for regions the aws sdk does not know about",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"bless_ca":       CA(),
-			"bless_ecdsa_ca": ECDSACA(),
+			"bless_ca":                   CA(),
+			"bless_ecdsa_ca":             ECDSACA(),
+			"bless_ed25519_ca":           Ed25519CA(),
+			"bless_ssh_certificate":      SSHCertificate(),
+			"bless_ssh_host_certificate": SSHHostCertificate(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"bless_kms_public_key": KMSPublicKey(),
+			"bless_caller_identity":      CallerIdentity(),
+			"bless_kms_public_key":       KMSPublicKey(),
+			"bless_kmsauth_token":        KmsauthToken(),
+			"bless_known_hosts":          KnownHosts(),
+			"bless_lambda_config":        LambdaConfig(),
+			"bless_trusted_user_ca_keys": TrustedUserCAKeys(),
 		},
 		ConfigureFunc: configureProvider,
 	}
 }
 
 func configureProvider(s *schema.ResourceData) (interface{}, error) {
-	return aws.NewClient(s)
+	client, err := aws.NewClient(s)
+	if err != nil {
+		return nil, err
+	}
+	err = client.ValidateAccountID(
+		listOfStrings(s.Get("allowed_account_ids").(*schema.Set).List()),
+		listOfStrings(s.Get("forbidden_account_ids").(*schema.Set).List()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
 }
@@ -3,14 +3,18 @@ package provider_test
 import (
 	"testing"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/provider"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type KMSMock struct {
@@ -30,19 +34,89 @@ func (k *KMSMock) GetPublicKey(input *kms.GetPublicKeyInput) (*kms.GetPublicKeyO
 	return output, args.Error(1)
 }
 
+func (k *KMSMock) Decrypt(input *kms.DecryptInput) (*kms.DecryptOutput, error) {
+	args := k.Called(input)
+	output := args.Get(0).(*kms.DecryptOutput)
+	return output, args.Error(1)
+}
+
+func (k *KMSMock) DescribeKey(input *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	args := k.Called(input)
+	output := args.Get(0).(*kms.DescribeKeyOutput)
+	return output, args.Error(1)
+}
+
+func (k *KMSMock) ReEncrypt(input *kms.ReEncryptInput) (*kms.ReEncryptOutput, error) {
+	args := k.Called(input)
+	output := args.Get(0).(*kms.ReEncryptOutput)
+	return output, args.Error(1)
+}
+
+func (k *KMSMock) Sign(input *kms.SignInput) (*kms.SignOutput, error) {
+	args := k.Called(input)
+	output := args.Get(0).(*kms.SignOutput)
+	return output, args.Error(1)
+}
+
+type STSMock struct {
+	stsiface.STSAPI
+	mock.Mock
+}
+
+func (s *STSMock) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	args := s.Called(input)
+	output := args.Get(0).(*sts.GetCallerIdentityOutput)
+	return output, args.Error(1)
+}
+
+func describeKeyOutput(keyState string) *kms.DescribeKeyOutput {
+	return &kms.DescribeKeyOutput{
+		KeyMetadata: &kms.KeyMetadata{
+			KeyId:    awssdk.String("key id"),
+			KeyState: awssdk.String(keyState),
+		},
+	}
+}
+
 func getTestProviders() (map[string]terraform.ResourceProvider, *KMSMock) {
+	providers, kmsMock, _ := getTestProvidersWithSTS()
+	return providers, kmsMock
+}
+
+func getTestProvidersWithSTS() (map[string]terraform.ResourceProvider, *KMSMock, *STSMock) {
 	ca := provider.Provider()
 	kmsMock := &KMSMock{}
+	stsMock := &STSMock{}
 	ca.ConfigureFunc = func(s *schema.ResourceData) (interface{}, error) {
 		client := &aws.Client{
-			KMS: aws.KMS{Svc: kmsMock},
+			KMS:    aws.KMS{Svc: kmsMock},
+			STS:    aws.STS{Svc: stsMock},
+			Region: s.Get("region").(string),
+			RegionalKMS: map[string]aws.KMS{
+				"us-west-2": aws.KMS{Svc: kmsMock},
+			},
+		}
+		err := client.ValidateAccountID(
+			setOfStrings(s.Get("allowed_account_ids").(*schema.Set)),
+			setOfStrings(s.Get("forbidden_account_ids").(*schema.Set)),
+		)
+		if err != nil {
+			return nil, err
 		}
 		return client, nil
 	}
 	providers := map[string]terraform.ResourceProvider{
 		"bless": ca,
 	}
-	return providers, kmsMock
+	return providers, kmsMock, stsMock
+}
+
+func setOfStrings(set *schema.Set) []string {
+	strings := []string{}
+	for _, v := range set.List() {
+		strings = append(strings, v.(string))
+	}
+	return strings
 }
 
 func TestProvider(t *testing.T) {
@@ -51,3 +125,33 @@ func TestProvider(t *testing.T) {
 	err := p.InternalValidate()
 	assert.Nil(err)
 }
+
+func TestProviderAssumeRoleDuration(t *testing.T) {
+	r := require.New(t)
+	p := provider.Provider()
+
+	durations := map[string]bool{
+		"15m": true,
+		"1h":  true,
+		"12h": true,
+		"14m": false,
+		"13h": false,
+	}
+	for duration, valid := range durations {
+		_, errs := p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+			"region": "us-east-1",
+			"assume_role": []interface{}{
+				map[string]interface{}{
+					"role_arn": "arn:aws:iam::123456789012:role/bless",
+					"duration": duration,
+				},
+			},
+		}))
+		if valid {
+			r.Empty(errs, duration)
+		} else {
+			r.Len(errs, 1, duration)
+			r.Contains(errs[0].Error(), "to be from 15m0s to 12h0m0s, got "+duration)
+		}
+	}
+}
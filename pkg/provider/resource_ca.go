@@ -1,12 +1,13 @@
 package provider
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 
-	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 )
 
@@ -15,95 +16,103 @@ const (
 	schemaEncryptedPrivateKey = "encrypted_ca"
 	schemaPublicKey           = "public_key"
 	schemaEncryptedPassword   = "encrypted_password"
+	schemaKeyBits             = "key_bits"
+	schemaPrivateKeyFormat    = "private_key_format"
+	schemaCompression         = "compression"
 
-	keySize         = 4096
+	schemaFingerprintSHA256 = "fingerprint_sha256"
+	schemaFingerprintMD5    = "fingerprint_md5"
+
+	schemaEncryptionContext            = "encryption_context"
+	schemaEncryptionContextFingerprint = "encryption_context_fingerprint"
+	schemaEffectiveEncryptionContext   = "effective_encryption_context"
+
+	schemaAdditionalKmsKeys  = "additional_kms_keys"
+	schemaRegion             = "region"
+	schemaName               = "name"
+	schemaEncryptedPasswords = "encrypted_passwords"
+
+	schemaVerifyOnRead  = "verify_on_read"
+	schemaKmsKeyState   = "kms_key_state"
+	schemaUnrecoverable = "unrecoverable"
+
+	schemaRotationPeriod = "rotation_period"
+	schemaRotateAfter    = "rotate_after"
+	schemaKeepers        = "keepers"
+	schemaCreatedAt      = "created_at"
+	schemaNextRotationAt = "next_rotation_at"
+
+	// encryptionContextFingerprintKey is the encryption context key for the CA fingerprint
+	encryptionContextFingerprintKey = "ca_fingerprint"
+
+	defaultKeyBits  = 4096
+	minKeyBits      = 2048
 	caPasswordBytes = 64
 )
 
+// rsaKeyBits are the rsa key sizes we allow for a CA
+var rsaKeyBits = []int{2048, 3072, 4096, 8192}
+
 // CA is a bless CA resource
 func CA() *schema.Resource {
 	ca := newResourceCA()
 	return &schema.Resource{
 		Create: ca.Create,
 		Read:   ca.Read,
+		Update: ca.Update,
 		Delete: ca.Delete,
 
-		Schema: map[string]*schema.Schema{
-			schemaKmsKeyID: &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The kms key with which we should encrypt the CA password.",
-				ForceNew:    true,
-			},
-
-			// computed
-			schemaEncryptedPrivateKey: &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "This is the base64 encoded CA encrypted private key.",
-			},
-			schemaPublicKey: &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "This is the plaintext CA public key in openssh format.",
-			},
-			schemaEncryptedPassword: &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "This is the kms encrypted password.",
-			},
+		CustomizeDiff: ca.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: ca.Import,
 		},
-	}
-}
-
-// resourceCA is a namespace
-type resourceCA struct{}
-
-func newResourceCA() *resourceCA {
-	return &resourceCA{}
-}
 
-// Create creates a CA
-func (ca *resourceCA) Create(d *schema.ResourceData, meta interface{}) error {
-	awsClient, ok := meta.(*aws.Client)
-	if !ok {
-		return errors.New("meta is not of type *aws.Client")
-	}
+		SchemaVersion: 1,
+		StateUpgraders: caStateUpgraders(map[string]interface{}{
+			// version 0 always generated 4096 bit keys
+			schemaKeyBits: 4096,
+		}),
 
-	kmsKeyID := d.Get(schemaKmsKeyID).(string)
-	keyPair, err := ca.createKeypair()
-	if err != nil {
-		return err
-	}
-	encryptedPassword, err := awsClient.KMS.EncryptBytes(keyPair.Password, kmsKeyID)
-	if err != nil {
-		return err
+		Schema: caSchema(map[string]*schema.Schema{
+			schemaKeyBits: &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultKeyBits,
+				Description:  "The size in bits of the CA rsa key.",
+				ForceNew:     true,
+				ValidateFunc: validation.IntInSlice(rsaKeyBits),
+			},
+		}),
 	}
-
-	d.Set(schemaEncryptedPrivateKey, keyPair.B64EncryptedPrivateKey) // nolint
-	d.Set(schemaPublicKey, keyPair.PublicKey) // nolint
-	d.Set(schemaEncryptedPassword, encryptedPassword) // nolint
-	d.SetId(util.HashForState(keyPair.PublicKey))
-	return nil
 }
 
-// Read reads the ca
-func (ca *resourceCA) Read(d *schema.ResourceData, meta interface{}) error {
-	return nil
+// resourceCA is a namespace
+type resourceCA struct {
+	baseCA
 }
 
-// Delete deletes the ca
-func (ca *resourceCA) Delete(d *schema.ResourceData, meta interface{}) error {
-	d.SetId("")
-	return nil
+func newResourceCA() *resourceCA {
+	ca := &resourceCA{}
+	ca.baseCA.createKeypair = ca.createKeypair
+	ca.baseCA.importKey = ca.importKey
+	return ca
 }
 
 // ------------ helpers ------------------
-func (ca *resourceCA) createKeypair() (*util.CA, error) {
+func (ca *resourceCA) createKeypair(d *schema.ResourceData) (*util.CA, error) {
 	// generate private key
-	privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
+	privateKey, err := rsa.GenerateKey(rand.Reader, d.Get(schemaKeyBits).(int))
 	if err != nil {
 		return nil, errors.Wrap(err, "Private key generation failed")
 	}
-	return util.NewCA(privateKey, privateKey.Public(), caPasswordBytes)
+	return util.NewCA(privateKey, privateKey.Public(), caPasswordBytes, privateKeyFormat(d), compression(d))
+}
+
+func (ca *resourceCA) importKey(d *schema.ResourceData, signer crypto.Signer) error {
+	privateKey, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return errors.New("CA private key is not an rsa key")
+	}
+	d.Set(schemaKeyBits, privateKey.N.BitLen()) // nolint
+	return nil
 }
@@ -1,16 +1,22 @@
 package provider_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"encoding/base64"
+	"encoding/json"
 	"regexp"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
 	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestCreate(t *testing.T) {
@@ -24,6 +30,7 @@ func TestCreate(t *testing.T) {
 		CiphertextBlob: ciphertext,
 	}
 	kmsMock.On("Encrypt", mock.Anything).Return(output, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
 
 	r.Test(t, r.TestCase{
 		Providers: providers,
@@ -73,3 +80,142 @@ func TestCreate(t *testing.T) {
 		},
 	})
 }
+
+func TestCreateKeyBits(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	output := &kms.EncryptOutput{
+		CiphertextBlob: []byte("ciphertext"),
+	}
+	kmsMock.On("Encrypt", mock.Anything).Return(output, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				resource "bless_ca" "bless" {
+					kms_key_id = "testo"
+					key_bits   = 1024
+				}
+			`,
+				ExpectError: regexp.MustCompile(`expected key_bits to be one of \[2048 3072 4096 8192\], got 1024`),
+			},
+			r.TestStep{
+				Config: `
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				resource "bless_ca" "bless" {
+					kms_key_id = "testo"
+					key_bits   = 2048
+				}
+
+				output "public_key" {
+					value = "${bless_ca.bless.public_key}"
+				}
+			`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("bless_ca.bless", "key_bits", "2048"),
+					func(s *terraform.State) error {
+						publicSSH, ok := s.RootModule().Outputs["public_key"].Value.(string)
+						a.True(ok)
+						pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicSSH))
+						a.NoError(err)
+						cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+						a.True(ok)
+						rsaPub, ok := cryptoPub.CryptoPublicKey().(*rsa.PublicKey)
+						a.True(ok)
+						a.Equal(2048, rsaPub.N.BitLen())
+
+						attributes := s.RootModule().Resources["bless_ca.bless"].Primary.Attributes
+						a.Equal(ssh.FingerprintSHA256(pub), attributes["fingerprint_sha256"])
+						a.Equal(ssh.FingerprintLegacyMD5(pub), attributes["fingerprint_md5"])
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestImport(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	// not the default key_bits, the imported CA reports the size of its key
+	privateKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	a.NoError(err)
+	ca, err := util.NewCA(privateKey, privateKey.Public(), 64, util.PrivateKeyFormatPEMRFC1423, util.CompressionNone)
+	a.NoError(err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	ecdsaCA, err := util.NewCA(ecdsaKey, ecdsaKey.Public(), 64, util.PrivateKeyFormatPEMRFC1423, util.CompressionNone)
+	a.NoError(err)
+
+	kmsMock.On("Decrypt", mock.MatchedBy(func(input *kms.DecryptInput) bool {
+		return string(input.CiphertextBlob) == "ciphertext"
+	})).Return(&kms.DecryptOutput{Plaintext: ca.Password}, nil)
+	kmsMock.On("Decrypt", mock.MatchedBy(func(input *kms.DecryptInput) bool {
+		return string(input.CiphertextBlob) == "ecdsa ciphertext"
+	})).Return(&kms.DecryptOutput{Plaintext: ecdsaCA.Password}, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	importID := func(ca *util.CA, ciphertext string) string {
+		id, err := json.Marshal(map[string]interface{}{
+			"kms_key_id":         "testo",
+			"encrypted_ca":       ca.B64EncryptedPrivateKey,
+			"encrypted_password": base64.StdEncoding.EncodeToString([]byte(ciphertext)),
+		})
+		a.NoError(err)
+		return string(id)
+	}
+
+	config := `
+	provider "bless" {
+		region = "us-east-1"
+	}
+
+	resource "bless_ca" "bless" {
+		kms_key_id = "testo"
+		key_bits   = 3072
+	}
+	`
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config:        config,
+				ResourceName:  "bless_ca.bless",
+				ImportState:   true,
+				ImportStateId: importID(ecdsaCA, "ecdsa ciphertext"),
+				ExpectError:   regexp.MustCompile(`CA private key is not an rsa key`),
+			},
+			r.TestStep{
+				Config:        config,
+				ResourceName:  "bless_ca.bless",
+				ImportState:   true,
+				ImportStateId: importID(ca, "ciphertext"),
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					a.Len(states, 1)
+					attributes := states[0].Attributes
+					a.Equal(util.HashForState(ca.PublicKey), states[0].ID)
+					a.Equal(ca.PublicKey, attributes["public_key"])
+					a.Equal("3072", attributes["key_bits"])
+					a.Equal("pem_rfc1423", attributes["private_key_format"])
+					a.Equal("none", attributes["compression"])
+					a.Equal("Enabled", attributes["kms_key_state"])
+					return nil
+				},
+			},
+		},
+	})
+}
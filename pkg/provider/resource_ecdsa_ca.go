@@ -1,101 +1,118 @@
 package provider
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 
-	"github.com/chanzuckerberg/terraform-provider-bless/pkg/aws"
 	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 )
 
+const (
+	schemaCurve   = "curve"
+	schemaKeyType = "key_type"
+
+	defaultCurve = "P521"
+)
+
+// ecdsaCurves are the curves we allow for an ecdsa CA
+var ecdsaCurves = map[string]elliptic.Curve{
+	"P256": elliptic.P256(),
+	"P384": elliptic.P384(),
+	"P521": elliptic.P521(),
+}
+
 // ECDSACA is an ecdsa CA resource
 func ECDSACA() *schema.Resource {
 	ca := newResourceECDSACA()
 	return &schema.Resource{
 		Create: ca.Create,
 		Read:   ca.Read,
+		Update: ca.Update,
 		Delete: ca.Delete,
 
-		Schema: map[string]*schema.Schema{
-			schemaKmsKeyID: &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The kms key with which we should encrypt the CA password.",
-				ForceNew:    true,
+		CustomizeDiff: ca.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: ca.Import,
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: caStateUpgraders(map[string]interface{}{
+			// version 0 always generated P521 keys
+			schemaCurve: "P521",
+		}),
+
+		Schema: caSchema(map[string]*schema.Schema{
+			schemaCurve: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultCurve,
+				Description:  "The elliptic curve of the CA key, one of P256, P384 or P521.",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"P256", "P384", "P521"}, false),
 			},
 
 			// computed
-			schemaEncryptedPrivateKey: &schema.Schema{
+			schemaKeyType: &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "This is the base64 encoded CA encrypted private key.",
+				Description: "This is the ssh key type of the CA, for example ecdsa-sha2-nistp384.",
 			},
-			schemaPublicKey: &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "This is the plaintext CA public key in openssh format.",
-			},
-			schemaEncryptedPassword: &schema.Schema{
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "This is the kms encrypted password.",
-			},
-		},
+		}),
 	}
 }
 
-// resourceCA is a namespace
-type resourceECDSACA struct{}
+// resourceECDSACA is a namespace
+type resourceECDSACA struct {
+	baseCA
+}
 
 func newResourceECDSACA() *resourceECDSACA {
-	return &resourceECDSACA{}
+	ca := &resourceECDSACA{}
+	ca.baseCA.createKeypair = ca.createKeypair
+	ca.baseCA.importKey = ca.importKey
+	return ca
 }
 
-// Create creates a CA
-func (ca *resourceECDSACA) Create(d *schema.ResourceData, meta interface{}) error {
-	awsClient, ok := meta.(*aws.Client)
+// ------------ helpers ------------------
+func (ca *resourceECDSACA) createKeypair(d *schema.ResourceData) (*util.CA, error) {
+	curveName := d.Get(schemaCurve).(string)
+	curve, ok := ecdsaCurves[curveName]
 	if !ok {
-		return errors.New("meta is not of type *aws.Client")
+		return nil, errors.Errorf("Unsupported curve %s", curveName)
 	}
-
-	kmsKeyID := d.Get(schemaKmsKeyID).(string)
-	keyPair, err := ca.createKeypair()
+	// generate private key
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "Private key generation failed")
 	}
-
-	encryptedPassword, err := awsClient.KMS.EncryptBytes(keyPair.Password, kmsKeyID)
+	keyPair, err := util.NewCA(privateKey, privateKey.Public(), caPasswordBytes, privateKeyFormat(d), compression(d))
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	d.Set(schemaEncryptedPrivateKey, keyPair.B64EncryptedPrivateKey) // nolint
-	d.Set(schemaPublicKey, keyPair.PublicKey) // nolint
-	d.Set(schemaEncryptedPassword, encryptedPassword) // nolint
-	d.SetId(util.HashForState(keyPair.PublicKey))
-	return nil
-}
-
-// Read reads the ca
-func (ca *resourceECDSACA) Read(d *schema.ResourceData, meta interface{}) error {
-	return nil
-}
-
-// Delete deletes the ca
-func (ca *resourceECDSACA) Delete(d *schema.ResourceData, meta interface{}) error {
-	d.SetId("")
-	return nil
+	d.Set(schemaKeyType, keyPair.KeyType) // nolint
+	return keyPair, nil
 }
 
-// ------------ helpers ------------------
-func (ca *resourceECDSACA) createKeypair() (*util.CA, error) {
-	// generate private key
-	privateKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+func (ca *resourceECDSACA) importKey(d *schema.ResourceData, signer crypto.Signer) error {
+	privateKey, ok := signer.(*ecdsa.PrivateKey)
+	if !ok {
+		return errors.New("CA private key is not an ecdsa key")
+	}
+	for name, curve := range ecdsaCurves {
+		if curve == privateKey.Curve {
+			d.Set(schemaCurve, name) // nolint
+		}
+	}
+	sshPublicKey, err := ssh.NewPublicKey(signer.Public())
 	if err != nil {
-		return nil, errors.Wrap(err, "Private key generation failed")
+		return errors.Wrap(err, "Could not generate openssh public key")
 	}
-	return util.NewCA(privateKey, privateKey.Public(), caPasswordBytes)
+	d.Set(schemaKeyType, sshPublicKey.Type()) // nolint
+	return nil
 }
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+// Ed25519CA is an ed25519 CA resource
+func Ed25519CA() *schema.Resource {
+	ca := newResourceEd25519CA()
+	return &schema.Resource{
+		Create: ca.Create,
+		Read:   ca.Read,
+		Update: ca.Update,
+		Delete: ca.Delete,
+
+		CustomizeDiff: ca.CustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: ca.Import,
+		},
+
+		Schema: caSchema(map[string]*schema.Schema{
+			// legacy PEM encryption has no ed25519 key encoding
+			schemaPrivateKeyFormat: &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     string(util.PrivateKeyFormatPKCS8PBES2),
+				Description: "The format of the encrypted CA private key, one of pkcs8_pbes2 or openssh.",
+				ForceNew:    true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(util.PrivateKeyFormatPKCS8PBES2),
+					string(util.PrivateKeyFormatOpenSSH),
+				}, false),
+			},
+		}),
+	}
+}
+
+// resourceEd25519CA is a namespace
+type resourceEd25519CA struct {
+	baseCA
+}
+
+func newResourceEd25519CA() *resourceEd25519CA {
+	ca := &resourceEd25519CA{}
+	ca.baseCA.createKeypair = ca.createKeypair
+	ca.baseCA.importKey = ca.importKey
+	return ca
+}
+
+// ------------ helpers ------------------
+func (ca *resourceEd25519CA) createKeypair(d *schema.ResourceData) (*util.CA, error) {
+	// generate private key
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Private key generation failed")
+	}
+	return util.NewCA(privateKey, publicKey, caPasswordBytes, privateKeyFormat(d), compression(d))
+}
+
+func (ca *resourceEd25519CA) importKey(d *schema.ResourceData, signer crypto.Signer) error {
+	if _, ok := signer.(ed25519.PrivateKey); !ok {
+		return errors.New("CA private key is not an ed25519 key")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	criticalOptionForceCommand  = "force-command"
+	criticalOptionSourceAddress = "source-address"
+)
+
+// SSHCertificate is an ssh user certificate resource
+func SSHCertificate() *schema.Resource {
+	certificate := newResourceSSHCertificate()
+	return &schema.Resource{
+		Create: certificate.Create,
+		Read:   certificate.Read,
+		Delete: certificate.Delete,
+
+		Schema: certificateSchema(map[string]*schema.Schema{
+			schemaValidAfter: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The RFC3339 timestamp the certificate is valid from, defaults to its creation.",
+				ForceNew:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			schemaExtensions: &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The certificate extensions, for example permit-pty, most extensions have an empty value.",
+				ForceNew:    true,
+			},
+			schemaCriticalOptions: &schema.Schema{
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Description:  "The certificate critical options, force-command and source-address.",
+				ForceNew:     true,
+				ValidateFunc: validateCriticalOptions,
+			},
+		}),
+	}
+}
+
+// resourceSSHCertificate is a namespace
+type resourceSSHCertificate struct{}
+
+func newResourceSSHCertificate() *resourceSSHCertificate {
+	return &resourceSSHCertificate{}
+}
+
+// Create signs a user certificate
+func (c *resourceSSHCertificate) Create(d *schema.ResourceData, meta interface{}) error {
+	validAfter := time.Now()
+	if v := d.Get(schemaValidAfter).(string); v != "" {
+		var err error
+		validAfter, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errors.Wrapf(err, "Could not parse %s", schemaValidAfter)
+		}
+	}
+	permissions := ssh.Permissions{
+		CriticalOptions: stringMap(d.Get(schemaCriticalOptions).(map[string]interface{})),
+		Extensions:      stringMap(d.Get(schemaExtensions).(map[string]interface{})),
+	}
+	err := issueCertificate(d, meta, ssh.UserCert, validAfter, permissions)
+	if err != nil {
+		return err
+	}
+	return c.Read(d, meta)
+}
+
+// Read is a noop, the certificate only lives in the state
+func (c *resourceSSHCertificate) Read(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// Delete removes the certificate from the state
+func (c *resourceSSHCertificate) Delete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
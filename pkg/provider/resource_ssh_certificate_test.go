@@ -0,0 +1,221 @@
+package provider_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// userPublicKey generates a public key to certify in authorized_keys format
+func userPublicKey(t *testing.T) string {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pub, err := ssh.NewPublicKey(priv.Public())
+	require.NoError(t, err)
+	return string(ssh.MarshalAuthorizedKey(pub))
+}
+
+// checkCertificate parses an authorized_keys formatted certificate and checks
+// it was signed by the authorized_keys formatted CA
+func checkCertificate(a *assert.Assertions, certificate string, ca string) *ssh.Certificate {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate))
+	a.NoError(err)
+	cert, ok := pub.(*ssh.Certificate)
+	a.True(ok)
+	caPub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(ca))
+	a.NoError(err)
+	a.Equal(caPub.Marshal(), cert.SignatureKey.Marshal())
+
+	checker := &ssh.CertChecker{
+		Clock: func() time.Time {
+			return time.Unix(int64(cert.ValidAfter)+1, 0)
+		},
+		SupportedCriticalOptions: []string{"force-command", "source-address"},
+	}
+	a.NoError(checker.CheckCert(cert.ValidPrincipals[0], cert))
+	return cert
+}
+
+func TestSSHCertificate(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	decryptOutput := &kms.DecryptOutput{}
+	kmsMock.On("Encrypt", mock.Anything).Run(func(args mock.Arguments) {
+		decryptOutput.Plaintext = args.Get(0).(*kms.EncryptInput).Plaintext
+	}).Return(&kms.EncryptOutput{CiphertextBlob: []byte("ciphertext")}, nil)
+	kmsMock.On("Decrypt", mock.Anything).Return(decryptOutput, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	publicKey := userPublicKey(t)
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				resource "bless_ca" "bless" {
+					kms_key_id = "testo"
+					key_bits   = 2048
+				}
+
+				resource "bless_ssh_certificate" "deploy" {
+					public_key         = %q
+					principals         = ["deploy"]
+					key_id             = "ci-deploy"
+					valid_after        = "2020-01-01T00:00:00Z"
+					validity_period    = "24h"
+					extensions         = { "permit-pty" = "" }
+					critical_options   = { "force-command" = "/usr/bin/deploy", "source-address" = "10.0.0.0/8,192.168.1.1" }
+					encrypted_ca       = bless_ca.bless.encrypted_ca
+					encrypted_password = bless_ca.bless.encrypted_password
+					encryption_context = bless_ca.bless.effective_encryption_context
+				}
+			`, publicKey),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("bless_ssh_certificate.deploy", "valid_before", "2020-01-02T00:00:00Z"),
+					r.TestCheckResourceAttrPair("bless_ssh_certificate.deploy", "ca_public_key", "bless_ca.bless", "public_key"),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["bless_ssh_certificate.deploy"].Primary.Attributes
+						cert := checkCertificate(a, attributes["certificate"], attributes["ca_public_key"])
+						a.Equal(uint32(ssh.UserCert), cert.CertType)
+						a.Equal(ssh.SigAlgoRSASHA2512, cert.Signature.Format)
+						a.Equal([]string{"deploy"}, cert.ValidPrincipals)
+						a.Equal("ci-deploy", cert.KeyId)
+						a.Equal(attributes["serial"], strconv.FormatUint(cert.Serial, 10))
+						a.Equal(uint64(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Unix()), cert.ValidAfter)
+						a.Equal(map[string]string{"permit-pty": ""}, cert.Extensions)
+						a.Equal(map[string]string{
+							"force-command":  "/usr/bin/deploy",
+							"source-address": "10.0.0.0/8,192.168.1.1",
+						}, cert.CriticalOptions)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestSSHCertificateKMSSigner(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	a.NoError(err)
+	derBytes, err := x509.MarshalPKIXPublicKey(caKey.Public())
+	a.NoError(err)
+	kmsMock.On("GetPublicKey", mock.Anything).Return(&kms.GetPublicKeyOutput{
+		PublicKey:             derBytes,
+		KeyId:                 aws.String("signing key"),
+		KeyUsage:              aws.String(kms.KeyUsageTypeSignVerify),
+		CustomerMasterKeySpec: aws.String(kms.CustomerMasterKeySpecEccNistP521),
+	}, nil)
+	signOutput := &kms.SignOutput{}
+	kmsMock.On("Sign", mock.MatchedBy(func(input *kms.SignInput) bool {
+		return aws.StringValue(input.MessageType) == kms.MessageTypeDigest &&
+			aws.StringValue(input.SigningAlgorithm) == kms.SigningAlgorithmSpecEcdsaSha512 &&
+			len(input.Message) == sha512.Size
+	})).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*kms.SignInput)
+		signOutput.Signature, err = caKey.Sign(rand.Reader, input.Message, crypto.SHA512)
+		a.NoError(err)
+	}).Return(signOutput, nil)
+
+	publicKey := userPublicKey(t)
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: fmt.Sprintf(`
+				provider "bless" {
+					region = "us-east-1"
+				}
+
+				data "bless_kms_public_key" "ca" {
+					kms_key_id = "signing key"
+				}
+
+				resource "bless_ssh_certificate" "deploy" {
+					public_key         = %q
+					principals         = ["deploy", "ci"]
+					key_id             = "ci-deploy"
+					validity_period    = "1h"
+					kms_signing_key_id = "signing key"
+				}
+			`, publicKey),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttrPair("bless_ssh_certificate.deploy", "ca_public_key", "data.bless_kms_public_key.ca", "public_key"),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["bless_ssh_certificate.deploy"].Primary.Attributes
+						cert := checkCertificate(a, attributes["certificate"], attributes["ca_public_key"])
+						a.Equal(ssh.KeyAlgoECDSA521, cert.Signature.Format)
+						a.Equal([]string{"deploy", "ci"}, cert.ValidPrincipals)
+						a.Equal(uint64(time.Hour.Seconds()), cert.ValidBefore-cert.ValidAfter)
+						a.Empty(cert.Extensions)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestSSHCertificateValidation(t *testing.T) {
+	providers, _ := getTestProviders()
+	publicKey := userPublicKey(t)
+
+	config := func(arguments string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		resource "bless_ssh_certificate" "deploy" {
+			public_key      = %q
+			principals      = ["deploy"]
+			key_id          = "ci-deploy"
+			validity_period = "1h"
+			%s
+		}
+		`, publicKey, arguments)
+	}
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config:      config(`kms_signing_key_id = "key"` + "\n" + `critical_options = { "permit-pty" = "" }`),
+				ExpectError: regexp.MustCompile("expected critical_options to only have force-command or source-address"),
+			},
+			r.TestStep{
+				Config:      config(`kms_signing_key_id = "key"` + "\n" + `critical_options = { "source-address" = "10.0.0.0/33" }`),
+				ExpectError: regexp.MustCompile("comma separated list of addresses or CIDRs"),
+			},
+			r.TestStep{
+				Config:      config(""),
+				ExpectError: regexp.MustCompile("one of `encrypted_ca,kms_signing_key_id` must be specified"),
+			},
+		},
+	})
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const certificateTypeHost = "host"
+
+// SSHHostCertificate is an ssh host certificate resource
+func SSHHostCertificate() *schema.Resource {
+	certificate := newResourceSSHHostCertificate()
+	return &schema.Resource{
+		Create: certificate.Create,
+		Read:   certificate.Read,
+		Update: certificate.Update,
+		Delete: certificate.Delete,
+
+		CustomizeDiff: certificate.CustomizeDiff,
+
+		Schema: certificateSchema(map[string]*schema.Schema{
+			schemaCertificateType: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      certificateTypeHost,
+				Description:  "The certificate type, host is the only type.",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{certificateTypeHost}, false),
+			},
+			schemaEarlyRenewal: &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "How long before valid_before the certificate is replaced, a duration such as 168h.",
+				ValidateFunc: validateDuration,
+			},
+
+			// computed
+			schemaValidAfter: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This is the RFC3339 timestamp the certificate is valid from.",
+			},
+		}),
+	}
+}
+
+// resourceSSHHostCertificate is a namespace
+type resourceSSHHostCertificate struct{}
+
+func newResourceSSHHostCertificate() *resourceSSHHostCertificate {
+	return &resourceSSHHostCertificate{}
+}
+
+// Create signs a host certificate valid from now
+func (c *resourceSSHHostCertificate) Create(d *schema.ResourceData, meta interface{}) error {
+	err := issueCertificate(d, meta, ssh.HostCert, time.Now(), ssh.Permissions{})
+	if err != nil {
+		return err
+	}
+	return c.Read(d, meta)
+}
+
+// Read is a noop, the certificate only lives in the state
+func (c *resourceSSHHostCertificate) Read(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// Update only changes early_renewal which is used when planning
+func (c *resourceSSHHostCertificate) Update(d *schema.ResourceData, meta interface{}) error {
+	return c.Read(d, meta)
+}
+
+// Delete removes the certificate from the state
+func (c *resourceSSHHostCertificate) Delete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// CustomizeDiff replaces the certificate once it is within its renewal window
+func (c *resourceSSHHostCertificate) CustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	validBefore, err := time.Parse(time.RFC3339, d.Get(schemaValidBefore).(string))
+	if err != nil {
+		return errors.Wrapf(err, "Could not parse %s", schemaValidBefore)
+	}
+	var earlyRenewal time.Duration
+	if v := d.Get(schemaEarlyRenewal).(string); v != "" {
+		earlyRenewal, err = time.ParseDuration(v)
+		if err != nil {
+			return errors.Wrapf(err, "Could not parse %s", schemaEarlyRenewal)
+		}
+	}
+	if time.Now().Before(validBefore.Add(-earlyRenewal)) {
+		return nil
+	}
+
+	err = d.SetNewComputed(schemaValidBefore)
+	if err != nil {
+		return errors.Wrapf(err, "Could not set %s", schemaValidBefore)
+	}
+	return errors.Wrapf(d.ForceNew(schemaValidBefore), "Could not force %s", schemaValidBefore)
+}
@@ -0,0 +1,87 @@
+package provider_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	r "github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHHostCertificateRenewal(t *testing.T) {
+	a := assert.New(t)
+	providers, kmsMock := getTestProviders()
+
+	decryptOutput := &kms.DecryptOutput{}
+	kmsMock.On("Encrypt", mock.Anything).Run(func(args mock.Arguments) {
+		decryptOutput.Plaintext = args.Get(0).(*kms.EncryptInput).Plaintext
+	}).Return(&kms.EncryptOutput{CiphertextBlob: []byte("ciphertext")}, nil)
+	kmsMock.On("Decrypt", mock.Anything).Return(decryptOutput, nil)
+	kmsMock.On("DescribeKey", mock.Anything).Return(describeKeyOutput(kms.KeyStateEnabled), nil)
+
+	publicKey := userPublicKey(t)
+	config := func(earlyRenewal string) string {
+		return fmt.Sprintf(`
+		provider "bless" {
+			region = "us-east-1"
+		}
+
+		resource "bless_ed25519_ca" "host" {
+			kms_key_id = "testo"
+		}
+
+		resource "bless_ssh_host_certificate" "bastion" {
+			public_key         = %q
+			principals         = ["bastion.example.com", "10.0.0.1"]
+			key_id             = "bastion"
+			validity_period    = "1h"
+			early_renewal      = "%s"
+			encrypted_ca       = bless_ed25519_ca.host.encrypted_ca
+			encrypted_password = bless_ed25519_ca.host.encrypted_password
+		}
+		`, publicKey, earlyRenewal)
+	}
+
+	var serial string
+	checkSerial := func(replaced bool) r.TestCheckFunc {
+		return func(s *terraform.State) error {
+			current := s.RootModule().Resources["bless_ssh_host_certificate.bastion"].Primary.Attributes["serial"]
+			if serial != "" {
+				a.Equal(replaced, current != serial)
+			}
+			serial = current
+			return nil
+		}
+	}
+
+	r.Test(t, r.TestCase{
+		Providers: providers,
+		Steps: []r.TestStep{
+			r.TestStep{
+				Config: config("30m"),
+				Check: r.ComposeTestCheckFunc(
+					checkSerial(false),
+					r.TestCheckResourceAttr("bless_ssh_host_certificate.bastion", "cert_type", "host"),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["bless_ssh_host_certificate.bastion"].Primary.Attributes
+						cert := checkCertificate(a, attributes["certificate"], attributes["ca_public_key"])
+						a.Equal(uint32(ssh.HostCert), cert.CertType)
+						a.Equal(ssh.KeyAlgoED25519, cert.Signature.Format)
+						a.Equal([]string{"bastion.example.com", "10.0.0.1"}, cert.ValidPrincipals)
+						return nil
+					},
+				),
+			},
+			r.TestStep{
+				// the certificate is now within its renewal window
+				Config:             config("2h"),
+				Check:              checkSerial(true),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"golang.org/x/crypto/ssh"
+)
+
+// validateRoleSessionName validates a sts role session name
+var validateRoleSessionName = validation.StringMatch(
+	regexp.MustCompile(`^[\w+=,.@-]{2,64}$`),
+	"must be 2 to 64 letters, digits or +=,.@-_",
+)
+
+// validateAccountID validates a 12 digit aws account id
+var validateAccountID = validation.StringMatch(
+	regexp.MustCompile(`^\d{12}$`),
+	"must be a 12 digit aws account id",
+)
+
+// regionPattern matches aws region names such as us-east-1 or us-gov-west-1
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+$`)
+
+// validateRegionKeys validates that every key of a map is an aws region
+func validateRegionKeys(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be map", k)}
+	}
+	var errs []error
+	for key := range v {
+		if !regionPattern.MatchString(key) {
+			errs = append(errs, fmt.Errorf("expected the keys of %s to be aws regions, got %s", k, key))
+		}
+	}
+	return nil, errs
+}
+
+// validateDuration validates a positive duration such as "2160h"
+func validateDuration(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a duration such as 2160h, got %s: %s", k, v, err)}
+	}
+	if duration <= 0 {
+		return nil, []error{fmt.Errorf("expected %s to be a positive duration, got %s", k, v)}
+	}
+	return nil, nil
+}
+
+// validateDurationBetween validates a duration such as "1h" from min to max
+func validateDurationBetween(min time.Duration, max time.Duration) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) ([]string, []error) {
+		warnings, errs := validateDuration(i, k)
+		if len(errs) > 0 {
+			return warnings, errs
+		}
+		duration, _ := time.ParseDuration(i.(string))
+		if duration < min || duration > max {
+			return warnings, []error{fmt.Errorf("expected %s to be from %s to %s, got %s", k, min, max, i)}
+		}
+		return warnings, nil
+	}
+}
+
+// validateAuthorizedKey validates a public key in authorized_keys format
+func validateAuthorizedKey(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(v))
+	if err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a public key in authorized_keys format: %s", k, err)}
+	}
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, []error{fmt.Errorf("expected %s to be a public key, got a certificate", k)}
+	}
+	return nil, nil
+}
+
+// validateCriticalOptions validates the certificate critical options we know how to issue
+func validateCriticalOptions(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be map", k)}
+	}
+	var errs []error
+	for option, value := range v {
+		switch option {
+		case criticalOptionForceCommand:
+		case criticalOptionSourceAddress:
+			for _, address := range strings.Split(value.(string), ",") {
+				_, _, err := net.ParseCIDR(address)
+				if err != nil && net.ParseIP(address) == nil {
+					errs = append(errs, fmt.Errorf("expected %s.%s to be a comma separated list of addresses or CIDRs, got %s", k, option, address))
+				}
+			}
+		default:
+			errs = append(errs, fmt.Errorf("expected %s to only have %s or %s, got %s", k, criticalOptionForceCommand, criticalOptionSourceAddress, option))
+		}
+	}
+	return nil, errs
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"crypto/rsa"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// AuthorizedKey is a parsed authorized_keys formatted public key
+type AuthorizedKey struct {
+	PublicKey         ssh.PublicKey
+	Comment           string
+	FingerprintSHA256 string
+}
+
+// Line is the key type and base64 encoded key without the comment
+func (k AuthorizedKey) Line() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(k.PublicKey)))
+}
+
+// CommentLine is a comment describing the key, for example "# SHA256:... bless_ca"
+func (k AuthorizedKey) CommentLine() string {
+	if k.Comment == "" {
+		return "# " + k.FingerprintSHA256
+	}
+	return "# " + k.FingerprintSHA256 + " " + k.Comment
+}
+
+// RSABits is the size of an rsa key, 0 for other key types
+func (k AuthorizedKey) RSABits() int {
+	cryptoPublicKey, ok := k.PublicKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+	rsaPublicKey, ok := cryptoPublicKey.CryptoPublicKey().(*rsa.PublicKey)
+	if !ok {
+		return 0
+	}
+	return rsaPublicKey.N.BitLen()
+}
+
+// ParseAuthorizedKeys parses authorized_keys formatted public keys, the result is
+// deduplicated and sorted by fingerprint so it does not depend on the input order.
+// Certificates are rejected since only plain keys can be CAs.
+func ParseAuthorizedKeys(authorizedKeys []string) ([]AuthorizedKey, error) {
+	seen := map[string]bool{}
+	parsed := []AuthorizedKey{}
+	for i, authorizedKey := range authorizedKeys {
+		publicKey, comment, _, rest, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse public key %d", i)
+		}
+		if len(strings.TrimSpace(string(rest))) > 0 {
+			return nil, errors.Errorf("public key %d has more than one key", i)
+		}
+		if _, ok := publicKey.(*ssh.Certificate); ok {
+			return nil, errors.Errorf("public key %d is a certificate", i)
+		}
+
+		fingerprint := ssh.FingerprintSHA256(publicKey)
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+		parsed = append(parsed, AuthorizedKey{
+			PublicKey:         publicKey,
+			Comment:           comment,
+			FingerprintSHA256: fingerprint,
+		})
+	}
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].FingerprintSHA256 < parsed[j].FingerprintSHA256
+	})
+	return parsed, nil
+}
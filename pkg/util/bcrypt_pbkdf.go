@@ -0,0 +1,97 @@
+package util
+
+import (
+	"crypto/sha512"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blowfish"
+)
+
+// bcryptPBKDFBlockSize is the output size of a single bcrypt hash
+const bcryptPBKDFBlockSize = 32
+
+var bcryptPBKDFMagic = []byte("OxychromaticBlowfishSwatDynamite")
+
+// bcryptPBKDF derives a key as OpenBSD's bcrypt_pbkdf(3) does, this is the kdf
+// OpenSSH uses to protect its private keys.
+// golang.org/x/crypto only ships this as an internal package.
+func bcryptPBKDF(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errors.New("bcrypt_pbkdf: number of rounds is too small")
+	}
+	if len(password) == 0 {
+		return nil, errors.New("bcrypt_pbkdf: empty password")
+	}
+	if len(salt) == 0 || len(salt) > 1<<20 {
+		return nil, errors.New("bcrypt_pbkdf: bad salt length")
+	}
+	if keyLen > 1024 {
+		return nil, errors.New("bcrypt_pbkdf: keyLen is too large")
+	}
+
+	numBlocks := (keyLen + bcryptPBKDFBlockSize - 1) / bcryptPBKDFBlockSize
+	key := make([]byte, numBlocks*bcryptPBKDFBlockSize)
+
+	h := sha512.New()
+	h.Write(password) // nolint
+	shaPassword := h.Sum(nil)
+
+	shaSalt := make([]byte, 0, sha512.Size)
+	count := make([]byte, 4)
+	tmp := make([]byte, bcryptPBKDFBlockSize)
+	for block := 1; block <= numBlocks; block++ {
+		h.Reset()
+		h.Write(salt) // nolint
+		count[0] = byte(block >> 24)
+		count[1] = byte(block >> 16)
+		count[2] = byte(block >> 8)
+		count[3] = byte(block)
+		h.Write(count) // nolint
+		err := bcryptHash(tmp, shaPassword, h.Sum(shaSalt))
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]byte, bcryptPBKDFBlockSize)
+		copy(out, tmp)
+		for i := 2; i <= rounds; i++ {
+			h.Reset()
+			h.Write(tmp) // nolint
+			err = bcryptHash(tmp, shaPassword, h.Sum(shaSalt))
+			if err != nil {
+				return nil, err
+			}
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+
+		// the output is spread across the key rather than concatenated
+		for i, v := range out {
+			key[i*numBlocks+(block-1)] = v
+		}
+	}
+	return key[:keyLen], nil
+}
+
+func bcryptHash(out, shaPassword, shaSalt []byte) error {
+	c, err := blowfish.NewSaltedCipher(shaPassword, shaSalt)
+	if err != nil {
+		return errors.Wrap(err, "Could not create blowfish cipher")
+	}
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(shaSalt, c)
+		blowfish.ExpandKey(shaPassword, c)
+	}
+	copy(out, bcryptPBKDFMagic)
+	for i := 0; i < bcryptPBKDFBlockSize; i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(out[i:i+8], out[i:i+8])
+		}
+	}
+	// blowfish works on big endian words, bcrypt_pbkdf expects little endian
+	for i := 0; i < bcryptPBKDFBlockSize; i += 4 {
+		out[i+3], out[i+2], out[i+1], out[i] = out[i], out[i+1], out[i+2], out[i+3]
+	}
+	return nil
+}
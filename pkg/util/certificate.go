@@ -0,0 +1,56 @@
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// rsaSHA2Signer signs with rsa-sha2-512 by default, OpenSSH no longer
+// accepts certificates signed with SHA-1
+type rsaSHA2Signer struct {
+	ssh.AlgorithmSigner
+}
+
+func (s rsaSHA2Signer) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, ssh.SigAlgoRSASHA2512)
+}
+
+// NewSSHSigner returns an ssh signer for a decrypted CA private key
+func NewSSHSigner(signer crypto.Signer) (ssh.Signer, error) {
+	sshSigner, err := ssh.NewSignerFromSigner(signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create ssh signer")
+	}
+	if sshSigner.PublicKey().Type() != ssh.KeyAlgoRSA {
+		return sshSigner, nil
+	}
+	algorithmSigner, ok := sshSigner.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, errors.New("rsa ssh signer does not support rsa-sha2-512")
+	}
+	return rsaSHA2Signer{algorithmSigner}, nil
+}
+
+// GenerateSerial generates a random certificate serial number
+func GenerateSerial() (uint64, error) {
+	b, err := GenerateRandomBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// SignCertificate signs the certificate with the CA signer and returns it in
+// authorized_keys format
+func SignCertificate(certificate *ssh.Certificate, signer ssh.Signer) (string, error) {
+	err := certificate.SignCert(rand.Reader, signer)
+	if err != nil {
+		return "", errors.Wrap(err, "Could not sign certificate")
+	}
+	return string(ssh.MarshalAuthorizedKey(certificate)), nil
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+
+	dsnetbzip2 "github.com/dsnet/compress/bzip2"
+	"github.com/pkg/errors"
+)
+
+// Compression is how the encrypted CA private key is compressed before it is
+// base64 encoded, BLESS calls this ca_private_key_compression
+type Compression string
+
+const (
+	// CompressionNone leaves the encrypted CA private key as is
+	CompressionNone Compression = "none"
+	// CompressionZlib is zlib (RFC 1950) as python's zlib module reads it
+	CompressionZlib Compression = "zlib"
+	// CompressionBZ2 is bzip2 as python's bz2 module reads it
+	CompressionBZ2 Compression = "bz2"
+)
+
+// Compressions are all the supported compressions
+var Compressions = []string{
+	string(CompressionNone),
+	string(CompressionZlib),
+	string(CompressionBZ2),
+}
+
+// Compress compresses data with the given compression
+func Compress(data []byte, compression Compression) ([]byte, error) {
+	var compressed bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionZlib:
+		var err error
+		w, err = zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not create zlib writer")
+		}
+	case CompressionBZ2:
+		var err error
+		w, err = dsnetbzip2.NewWriter(&compressed, &dsnetbzip2.WriterConfig{Level: dsnetbzip2.BestCompression})
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not create bz2 writer")
+		}
+	default:
+		return nil, errors.Errorf("Unrecognized compression %s", compression)
+	}
+
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not %s compress", compression)
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not %s compress", compression)
+	}
+	return compressed.Bytes(), nil
+}
+
+// DetectCompression detects the compression of data from its header
+func DetectCompression(data []byte) Compression {
+	switch {
+	case len(data) >= 4 && bytes.HasPrefix(data, []byte("BZh")) && data[3] >= '1' && data[3] <= '9':
+		return CompressionBZ2
+	case len(data) >= 2 && data[0]&0x0f == 8 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0:
+		// deflate with a valid header checksum, see RFC 1950 section 2.2
+		return CompressionZlib
+	default:
+		return CompressionNone
+	}
+}
+
+// Decompress decompresses data, the compression is detected from its header
+func Decompress(data []byte) ([]byte, Compression, error) {
+	compression := DetectCompression(data)
+	var r io.Reader
+	switch compression {
+	case CompressionBZ2:
+		r = bzip2.NewReader(bytes.NewReader(data))
+	case CompressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, compression, errors.Wrap(err, "Could not create zlib reader")
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return data, compression, nil
+	}
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, compression, errors.Wrapf(err, "Could not %s decompress", compression)
+	}
+	return decompressed, compression, nil
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// INIOption is a key = value line of an INI section
+type INIOption struct {
+	Key   string
+	Value string
+}
+
+// INISection is a named INI section, options keep their order
+type INISection struct {
+	Name    string
+	Options []INIOption
+}
+
+// RenderINI renders the sections in order as an INI file python's configparser reads
+func RenderINI(sections []INISection) (string, error) {
+	var ini strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			ini.WriteString("\n")
+		}
+		ini.WriteString("[" + section.Name + "]\n")
+		for _, option := range section.Options {
+			if strings.ContainsAny(option.Key, "\r\n=:[]") || strings.TrimSpace(option.Key) != option.Key {
+				return "", errors.Errorf("Invalid INI key %q in section %s", option.Key, section.Name)
+			}
+			if strings.ContainsAny(option.Value, "\r\n") {
+				return "", errors.Errorf("INI value of %s in section %s can not have newlines", option.Key, section.Name)
+			}
+			ini.WriteString(option.Key + " = " + option.Value + "\n")
+		}
+	}
+	return ini.String(), nil
+}
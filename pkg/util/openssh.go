@@ -0,0 +1,146 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	openSSHMagic      = "openssh-key-v1\x00"
+	openSSHCipher     = "aes256-ctr"
+	openSSHKDF        = "bcrypt"
+	openSSHKDFRounds  = 16
+	openSSHSaltBytes  = 16
+	openSSHKeyIVBytes = 32 + aes.BlockSize
+)
+
+// encryptOpenSSH marshals the private key in the OpenSSH private key format,
+// encrypted with aes256-ctr and a bcrypt_pbkdf derived key.
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func encryptOpenSSH(privateKey interface{}, password []byte) (*pem.Block, error) {
+	keyType, keyFields, publicKey, err := openSSHKeyFields(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not generate openssh public key")
+	}
+
+	checkBytes, err := GenerateRandomBytes(4)
+	if err != nil {
+		return nil, err
+	}
+	check := binary.BigEndian.Uint32(checkBytes)
+	privKeyBlock := ssh.Marshal(struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Rest    []byte `ssh:"rest"`
+	}{check, check, keyType, keyFields})
+	// the comment is empty, then pad 1, 2, 3... to the cipher block size
+	privKeyBlock = append(privKeyBlock, ssh.Marshal(struct{ Comment string }{})...)
+	for i := 1; len(privKeyBlock)%aes.BlockSize != 0; i++ {
+		privKeyBlock = append(privKeyBlock, byte(i))
+	}
+
+	salt, err := GenerateRandomBytes(openSSHSaltBytes)
+	if err != nil {
+		return nil, err
+	}
+	keyIV, err := bcryptPBKDF(password, salt, openSSHKDFRounds, openSSHKeyIVBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not derive private key encryption key")
+	}
+	block, err := aes.NewCipher(keyIV[:32])
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create cipher")
+	}
+	cipher.NewCTR(block, keyIV[32:]).XORKeyStream(privKeyBlock, privKeyBlock)
+
+	kdfOpts := ssh.Marshal(struct {
+		Salt   []byte
+		Rounds uint32
+	}{salt, openSSHKDFRounds})
+	key := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{openSSHCipher, openSSHKDF, string(kdfOpts), 1, sshPublicKey.Marshal(), privKeyBlock})
+
+	return &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte(openSSHMagic), key...),
+	}, nil
+}
+
+// openSSHKeyFields returns the key type and the key specific private fields
+func openSSHKeyFields(privateKey interface{}) (string, []byte, interface{}, error) {
+	switch typed := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if len(typed.Primes) != 2 {
+			return "", nil, nil, errors.New("Multi-prime rsa keys are not supported")
+		}
+		typed.Precompute()
+		return ssh.KeyAlgoRSA, ssh.Marshal(struct {
+			N    *big.Int
+			E    *big.Int
+			D    *big.Int
+			Iqmp *big.Int
+			P    *big.Int
+			Q    *big.Int
+		}{
+			typed.N,
+			big.NewInt(int64(typed.E)),
+			typed.D,
+			typed.Precomputed.Qinv,
+			typed.Primes[0],
+			typed.Primes[1],
+		}), typed.Public(), nil
+	case *ecdsa.PrivateKey:
+		var keyType, curve string
+		switch typed.Curve {
+		case elliptic.P256():
+			keyType, curve = ssh.KeyAlgoECDSA256, "nistp256"
+		case elliptic.P384():
+			keyType, curve = ssh.KeyAlgoECDSA384, "nistp384"
+		case elliptic.P521():
+			keyType, curve = ssh.KeyAlgoECDSA521, "nistp521"
+		default:
+			return "", nil, nil, errors.New("Unsupported elliptic curve")
+		}
+		return keyType, ssh.Marshal(struct {
+			Curve string
+			Pub   []byte
+			D     *big.Int
+		}{
+			curve,
+			elliptic.Marshal(typed.Curve, typed.X, typed.Y),
+			typed.D,
+		}), typed.Public(), nil
+	case ed25519.PrivateKey:
+		publicKey := typed.Public().(ed25519.PublicKey)
+		return ssh.KeyAlgoED25519, ssh.Marshal(struct {
+			Pub  []byte
+			Priv []byte
+		}{
+			publicKey,
+			typed,
+		}), publicKey, nil
+	default:
+		return "", nil, nil, errors.New("Unrecognized private key type")
+	}
+}
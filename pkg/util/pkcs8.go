@@ -0,0 +1,182 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pbkdf2Iterations = 100000
+	pbkdf2SaltBytes  = 16
+	aes256KeyBytes   = 32
+)
+
+var (
+	// https://tools.ietf.org/html/rfc8018#appendix-A.4
+	oidPBES2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	// https://tools.ietf.org/html/rfc8018#appendix-A.2
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	// https://tools.ietf.org/html/rfc8018#appendix-B.1.2
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	// https://tools.ietf.org/html/rfc8018#appendix-B.2.5
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type pkcs8AlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkcs8AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkcs8AlgorithmIdentifier
+	EncryptionScheme  pkcs8AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	PRF            pkcs8AlgorithmIdentifier `asn1:"optional"`
+}
+
+// encryptPKCS8 encrypts a DER PKCS#8 private key with PBES2 (PBKDF2-HMAC-SHA256
+// and AES-256-CBC) and returns it as an ENCRYPTED PRIVATE KEY PEM block
+func encryptPKCS8(der []byte, password []byte) (*pem.Block, error) {
+	salt, err := GenerateRandomBytes(pbkdf2SaltBytes)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := GenerateRandomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2.Key(password, salt, pbkdf2Iterations, aes256KeyBytes, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create cipher")
+	}
+	// PKCS#7 padding
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plaintext := make([]byte, len(der), len(der)+padding)
+	copy(plaintext, der)
+	for i := 0; i < padding; i++ {
+		plaintext = append(plaintext, byte(padding))
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF: pkcs8AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.RawValue{FullBytes: asn1.NullBytes},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not marshal pbkdf2 parameters")
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not marshal cipher parameters")
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkcs8AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkcs8AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not marshal pbes2 parameters")
+	}
+	encrypted, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: pkcs8AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: ciphertext,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not marshal encrypted private key")
+	}
+	return &pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}, nil
+}
+
+// decryptPKCS8 decrypts a PBES2 (PBKDF2-HMAC-SHA256 and AES-256-CBC) encrypted
+// PKCS#8 block and returns the DER PKCS#8 private key
+func decryptPKCS8(block *pem.Block, password []byte) ([]byte, error) {
+	var encrypted encryptedPrivateKeyInfo
+	_, err := asn1.Unmarshal(block.Bytes, &encrypted)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not unmarshal encrypted private key")
+	}
+	if !encrypted.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errors.Errorf("Unsupported encryption algorithm %s", encrypted.EncryptionAlgorithm.Algorithm)
+	}
+	var params pbes2Params
+	_, err = asn1.Unmarshal(encrypted.EncryptionAlgorithm.Parameters.FullBytes, &params)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not unmarshal pbes2 parameters")
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.Errorf("Unsupported key derivation function %s", params.KeyDerivationFunc.Algorithm)
+	}
+	if !params.EncryptionScheme.Algorithm.Equal(oidAES256CBC) {
+		return nil, errors.Errorf("Unsupported encryption scheme %s", params.EncryptionScheme.Algorithm)
+	}
+	var kdfParams pbkdf2Params
+	_, err = asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not unmarshal pbkdf2 parameters")
+	}
+	if !kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA256) {
+		return nil, errors.Errorf("Unsupported pbkdf2 prf %s", kdfParams.PRF.Algorithm)
+	}
+	var iv []byte
+	_, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not unmarshal cipher parameters")
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("Invalid cipher iv")
+	}
+
+	ciphertext := encrypted.EncryptedData
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("Invalid encrypted private key length")
+	}
+	key := pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, aes256KeyBytes, sha256.New)
+	aesBlock, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create cipher")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(aesBlock, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, x509.IncorrectPasswordError
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, x509.IncorrectPasswordError
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
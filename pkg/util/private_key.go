@@ -0,0 +1,201 @@
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// PrivateKeyFormat is how the CA private key is encrypted
+type PrivateKeyFormat string
+
+const (
+	// PrivateKeyFormatPEMRFC1423 is the legacy encrypted PEM (RFC 1423) format BLESS expects
+	PrivateKeyFormatPEMRFC1423 PrivateKeyFormat = "pem_rfc1423"
+	// PrivateKeyFormatPKCS8PBES2 is PKCS#8 encrypted with PBES2 (PBKDF2-HMAC-SHA256, AES-256-CBC)
+	PrivateKeyFormatPKCS8PBES2 PrivateKeyFormat = "pkcs8_pbes2"
+	// PrivateKeyFormatOpenSSH is the OpenSSH private key format (bcrypt_pbkdf, aes256-ctr)
+	PrivateKeyFormatOpenSSH PrivateKeyFormat = "openssh"
+)
+
+// PrivateKeyFormats are all the supported private key formats
+var PrivateKeyFormats = []string{
+	string(PrivateKeyFormatPEMRFC1423),
+	string(PrivateKeyFormatPKCS8PBES2),
+	string(PrivateKeyFormatOpenSSH),
+}
+
+// EncryptPrivateKey encrypts the private key with password in the given format
+func EncryptPrivateKey(privateKey crypto.PrivateKey, password []byte, format PrivateKeyFormat) (*pem.Block, error) {
+	switch format {
+	case PrivateKeyFormatPEMRFC1423:
+		return encryptPEMBlock(privateKey, password)
+	case PrivateKeyFormatPKCS8PBES2:
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not x509 Marshal private key")
+		}
+		block, err := encryptPKCS8(der, password)
+		return block, errors.Wrap(err, "Failed to encrypt CA")
+	case PrivateKeyFormatOpenSSH:
+		block, err := encryptOpenSSH(privateKey, password)
+		return block, errors.Wrap(err, "Failed to encrypt CA")
+	default:
+		return nil, errors.Errorf("Unrecognized private key format %s", format)
+	}
+}
+
+// decodeCA base64 decodes and decompresses a CA private key
+func decodeCA(b64EncryptedPrivateKey string) ([]byte, Compression, error) {
+	compressed, err := base64.StdEncoding.DecodeString(b64EncryptedPrivateKey)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Could not base64 decode CA")
+	}
+	return Decompress(compressed)
+}
+
+// DetectCACompression returns the compression of a base64 encoded encrypted CA private key
+func DetectCACompression(b64EncryptedPrivateKey string) (Compression, error) {
+	_, compression, err := decodeCA(b64EncryptedPrivateKey)
+	return compression, err
+}
+
+// DecryptCA decrypts a base64 encoded, optionally compressed, CA private key in
+// any of the supported formats
+func DecryptCA(b64EncryptedPrivateKey string, password []byte) (crypto.Signer, error) {
+	pemBytes, _, err := decodeCA(b64EncryptedPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("Could not PEM decode CA")
+	}
+
+	var privateKey interface{}
+	switch block.Type {
+	case "ENCRYPTED PRIVATE KEY":
+		der, err := decryptPKCS8(block, password)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to decrypt CA")
+		}
+		privateKey, err = x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not parse CA private key")
+		}
+	default:
+		// handles both legacy PEM encryption and the OpenSSH format
+		privateKey, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, password)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to decrypt CA")
+		}
+	}
+
+	// the ssh package returns ed25519 keys as pointers
+	if typed, ok := privateKey.(*ed25519.PrivateKey); ok {
+		privateKey = *typed
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("Unrecognized private key type")
+	}
+	return signer, nil
+}
+
+// DetectPrivateKeyFormat returns the format of a base64 encoded encrypted CA private key
+func DetectPrivateKeyFormat(b64EncryptedPrivateKey string) (PrivateKeyFormat, error) {
+	pemBytes, _, err := decodeCA(b64EncryptedPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return "", errors.New("Could not PEM decode CA")
+	}
+	switch block.Type {
+	case "ENCRYPTED PRIVATE KEY":
+		return PrivateKeyFormatPKCS8PBES2, nil
+	case "OPENSSH PRIVATE KEY":
+		return PrivateKeyFormatOpenSSH, nil
+	case "RSA PRIVATE KEY", "EC PRIVATE KEY":
+		// nolint: staticcheck // kept for BLESS compatibility
+		if !x509.IsEncryptedPEMBlock(block) {
+			return "", errors.New("CA private key is not encrypted")
+		}
+		return PrivateKeyFormatPEMRFC1423, nil
+	default:
+		return "", errors.Errorf("Unrecognized CA private key type %s", block.Type)
+	}
+}
+
+// FingerprintSHA256 returns the OpenSSH SHA256 fingerprint of an authorized_keys formatted public key
+func FingerprintSHA256(authorizedKey string) (string, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", errors.Wrap(err, "Could not parse public key")
+	}
+	return ssh.FingerprintSHA256(publicKey), nil
+}
+
+// FingerprintMD5 returns the OpenSSH legacy MD5 fingerprint of an authorized_keys formatted public key
+func FingerprintMD5(authorizedKey string) (string, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", errors.Wrap(err, "Could not parse public key")
+	}
+	return ssh.FingerprintLegacyMD5(publicKey), nil
+}
+
+// PublicKeyMatches returns true if the signer's public key is the authorized_keys
+// formatted public key
+func PublicKeyMatches(signer crypto.Signer, authorizedKey string) (bool, error) {
+	expected, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return false, errors.Wrap(err, "Could not parse public key")
+	}
+	actual, err := ssh.NewPublicKey(signer.Public())
+	if err != nil {
+		return false, errors.Wrap(err, "Could not generate openssh public key")
+	}
+	return bytes.Equal(expected.Marshal(), actual.Marshal()), nil
+}
+
+// encryptPEMBlock encrypts the private key as a legacy RFC 1423 PEM block
+func encryptPEMBlock(privateKey crypto.PrivateKey, password []byte) (*pem.Block, error) {
+	var blockType string
+	var der []byte
+	switch typed := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		var err error
+		blockType = "EC PRIVATE KEY"
+		der, err = x509.MarshalECPrivateKey(typed)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not x509 Marshal private key")
+		}
+	case *rsa.PrivateKey:
+		blockType = "RSA PRIVATE KEY"
+		der = x509.MarshalPKCS1PrivateKey(typed)
+	case ed25519.PrivateKey:
+		return nil, errors.Errorf("%s does not support ed25519 keys", PrivateKeyFormatPEMRFC1423)
+	default:
+		return nil, errors.New("Unrecognized private key type")
+	}
+
+	// nolint: staticcheck // kept for BLESS compatibility
+	block, err := x509.EncryptPEMBlock(
+		rand.Reader,
+		blockType,
+		der,
+		password,
+		x509.PEMCipherAES256)
+	return block, errors.Wrap(err, "Failed to encrypt CA")
+}
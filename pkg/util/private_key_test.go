@@ -0,0 +1,61 @@
+package util_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/chanzuckerberg/terraform-provider-bless/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCADecryptCA(t *testing.T) {
+	r := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	r.NoError(err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	r.NoError(err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	r.NoError(err)
+
+	keys := map[string]crypto.Signer{
+		"rsa":     rsaKey,
+		"ecdsa":   ecdsaKey,
+		"ed25519": ed25519Key,
+	}
+	for name, key := range keys {
+		for _, format := range util.PrivateKeyFormats {
+			format := util.PrivateKeyFormat(format)
+			if name == "ed25519" && format == util.PrivateKeyFormatPEMRFC1423 {
+				_, err = util.NewCA(key, key.Public(), 64, format, util.CompressionNone)
+				r.Error(err)
+				continue
+			}
+
+			for _, compression := range util.Compressions {
+				compression := util.Compression(compression)
+				ca, err := util.NewCA(key, key.Public(), 64, format, compression)
+				r.NoError(err, "%s %s %s", name, format, compression)
+
+				decrypted, err := util.DecryptCA(ca.B64EncryptedPrivateKey, ca.Password)
+				r.NoError(err, "%s %s %s", name, format, compression)
+				r.Equal(key.Public(), decrypted.Public(), "%s %s %s", name, format, compression)
+
+				detectedFormat, err := util.DetectPrivateKeyFormat(ca.B64EncryptedPrivateKey)
+				r.NoError(err)
+				r.Equal(format, detectedFormat)
+				detectedCompression, err := util.DetectCACompression(ca.B64EncryptedPrivateKey)
+				r.NoError(err)
+				r.Equal(compression, detectedCompression)
+
+				_, err = util.DecryptCA(ca.B64EncryptedPrivateKey, []byte("wrong password"))
+				r.Error(err, "%s %s %s", name, format, compression)
+			}
+		}
+	}
+}
@@ -3,12 +3,9 @@ package util
 import (
 	"bytes"
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
@@ -58,49 +55,39 @@ func GenerateRandomBytes(n uint) ([]byte, error) {
 // CA has information around a CA
 type CA struct {
 	PublicKey              string
+	KeyType                string
+	FingerprintSHA256      string
+	FingerprintMD5         string
 	B64EncryptedPrivateKey string
 	Password               []byte
 }
 
-// NewCA generates the CA components from a private key
-func NewCA(privateKey crypto.PrivateKey, publicKey interface{}, passwordBytes uint) (*CA, error) {
-	block := &pem.Block{}
-
-	switch typed := privateKey.(type) {
-	case *ecdsa.PrivateKey:
-		block.Type = "EC PRIVATE KEY"
-		bytes, err := x509.MarshalECPrivateKey(typed)
-		if err != nil {
-			return nil, errors.Wrap(err, "Could not x509 Marshal private key")
-		}
-		block.Bytes = bytes
-	case *rsa.PrivateKey:
-		block.Type = "RSA PRIVATE KEY"
-		block.Bytes = x509.MarshalPKCS1PrivateKey(typed)
-	default:
-		return nil, errors.New("Unrecognized private key type")
-	}
-
+// NewCA generates the CA components from a private key, the private key is
+// encrypted in the given format and compressed before it is base64 encoded
+func NewCA(
+	privateKey crypto.PrivateKey,
+	publicKey interface{},
+	passwordBytes uint,
+	format PrivateKeyFormat,
+	compression Compression) (*CA, error) {
 	password, err := GenerateRandomBytes(passwordBytes)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not generate private key password")
 	}
 
-	// encrypt the private key
-	block, err = x509.EncryptPEMBlock(
-		rand.Reader,
-		block.Type,
-		block.Bytes,
-		password,
-		x509.PEMCipherAES256)
+	block, err := EncryptPrivateKey(privateKey, password, format)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to encrypt CA")
+		return nil, err
 	}
 	var encryptedPEMBytes bytes.Buffer
 	err = pem.Encode(&encryptedPEMBytes, block)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not PEM encode encrypted CA")
 	}
+	compressed, err := Compress(encryptedPEMBytes.Bytes(), compression)
+	if err != nil {
+		return nil, err
+	}
 
 	sshPublicKey, err := ssh.NewPublicKey(publicKey)
 	if err != nil {
@@ -108,7 +95,10 @@ func NewCA(privateKey crypto.PrivateKey, publicKey interface{}, passwordBytes ui
 	}
 	return &CA{
 		PublicKey:              string(ssh.MarshalAuthorizedKey(sshPublicKey)),
-		B64EncryptedPrivateKey: base64.StdEncoding.EncodeToString(encryptedPEMBytes.Bytes()),
+		KeyType:                sshPublicKey.Type(),
+		FingerprintSHA256:      ssh.FingerprintSHA256(sshPublicKey),
+		FingerprintMD5:         ssh.FingerprintLegacyMD5(sshPublicKey),
+		B64EncryptedPrivateKey: base64.StdEncoding.EncodeToString(compressed),
 		Password:               password,
 	}, nil
 }